@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+)
+
+// runA11y reports missing or poor alt text across all published documents.
+func runA11y(args []string) error {
+	flags := flag.NewFlagSet("a11y", flag.ContinueOnError)
+	strict := flags.Bool("strict", false, "exit with an error when any document has warnings")
+	if err := flags.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	documents, err := helpers.LoadDocuments()
+	if err != nil {
+		return err
+	}
+
+	flagged, total := 0, 0
+	for _, document := range documents {
+		warnings := helpers.AccessibilityWarnings(document.Data)
+		if len(warnings) == 0 {
+			continue
+		}
+		flagged++
+		total += len(warnings)
+		fmt.Printf("%s:\n", document.Path)
+		for _, warning := range warnings {
+			fmt.Printf("  %s\n", warning)
+		}
+	}
+
+	fmt.Printf("%d warnings in %d of %d documents\n", total, flagged, len(documents))
+	if *strict && flagged > 0 {
+		return fmt.Errorf("accessibility check failed")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Package commands implements the command-line tasks that run instead of
+// the GUI when the publisher is started with arguments.
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"a11y":         {summary: "report missing or overly long alt text in output/", run: runA11y},
+	"gc":           {summary: "delete bucket objects no published document references", run: runGC},
+	"index":        {summary: "regenerate output/index.json with every document, tag and person", run: runIndex},
+	"people":       {summary: "list the documents each person wrote or attended", run: runPeople},
+	"presign":      {summary: "print pre-signed URLs for files in a private bucket", run: runPresign},
+	"rewrite-urls": {summary: "store object keys and rebuild bucket URLs in output/ from the configured base", run: runRewriteURLs},
+	"search":       {summary: "full-text search over the documents in output/", run: runSearch},
+	"tags":         {summary: "list the tags used in output/ with their counts", run: runTags},
+}
+
+// Run executes the command named by the first argument.
+func Run(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Print(usage())
+		return nil
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage())
+	}
+	return cmd.run(args[1:])
+}
+
+func usage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("Usage: lambda-hikes-trailfinder-json-publisher-go-app [command] [flags]\n\nCommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-12s %s\n", name, commands[name].summary)
+	}
+	b.WriteString("\nRun without a command to start the publisher window.\n")
+	return b.String()
+}
+
+// ignoreHelp treats -h as a successful run; the flag set already printed
+// its defaults.
+func ignoreHelp(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+)
+
+// runGC deletes objects under the document and draft prefixes that no
+// document in the output folder references.
+func runGC(args []string) error {
+	flags := flag.NewFlagSet("gc", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only report unreferenced objects")
+	all := flags.Bool("all", false, "scan the whole bucket, including prefixes of renamed or deleted documents")
+	minAge := flags.Duration("min-age", 24*time.Hour, "keep objects modified more recently than this")
+	if err := flags.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	// Objects referenced by a document that could not be read, or through a
+	// URL whose base is unknown, would look unreferenced; nothing is deleted
+	// then.
+	problems := 0
+	documents, err := helpers.LoadDocuments()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		problems++
+	}
+	if len(documents) == 0 {
+		return fmt.Errorf("no documents found in %s, run gc from the publisher folder", helpers.OutputFolder)
+	}
+
+	referenced := make(map[string]bool)
+	prefixes := []string{helpers.DraftPrefix + "/"}
+	for _, document := range documents {
+		prefixes = append(prefixes, document.ID+"/")
+		keys, unrecognized := document.ReferencedKeys()
+		for _, key := range keys {
+			referenced[key] = true
+		}
+		for _, url := range unrecognized {
+			fmt.Fprintf(os.Stderr, "%s: unknown object URL base in %s\n", document.Path, url)
+			problems++
+		}
+	}
+	if *all {
+		prefixes = []string{""}
+	}
+
+	ctx := context.Background()
+	cutoff := time.Now().Add(-*minAge)
+	seen := make(map[string]bool)
+	var orphans []string
+	var orphanBytes int64
+	for _, prefix := range prefixes {
+		objects, err := helpers.ListObjects(ctx, prefix)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			if seen[object.Key] || referenced[object.Key] || object.LastModified.After(cutoff) {
+				continue
+			}
+			seen[object.Key] = true
+			orphans = append(orphans, object.Key)
+			orphanBytes += object.Size
+			fmt.Println(object.Key)
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("%d unreferenced objects (%d bytes) would be deleted\n", len(orphans), orphanBytes)
+		return nil
+	}
+	if problems > 0 {
+		return fmt.Errorf("not deleting %d unreferenced objects: fix the %d problems above, or run rewrite-urls -from with the old base first", len(orphans), problems)
+	}
+	if err := helpers.DeleteObjects(ctx, orphans); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted %d unreferenced objects (%d bytes)\n", len(orphans), orphanBytes)
+	return nil
+}
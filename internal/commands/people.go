@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+)
+
+// runIndex regenerates the document index in the output folder.
+func runIndex(args []string) error {
+	flags := flag.NewFlagSet("index", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	index, err := helpers.WriteIndex()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s/%s with %d documents, %d tags and %d people\n", helpers.OutputFolder, helpers.IndexFile, len(index.Documents), len(index.Tags), len(index.People))
+	return nil
+}
+
+// runPeople prints the documents each person wrote or took part in.
+func runPeople(args []string) error {
+	flags := flag.NewFlagSet("people", flag.ContinueOnError)
+	name := flags.String("name", "", "only list people whose name contains this text")
+	if err := flags.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	listed := 0
+	for _, person := range helpers.ListPeople(helpers.DocumentIndex().All()) {
+		if !strings.Contains(strings.ToLower(person.Name), strings.ToLower(*name)) {
+			continue
+		}
+		listed++
+		fmt.Println(person.Name)
+		printRefs("wrote", person.Authored)
+		printRefs("attended", person.Participated)
+	}
+	fmt.Printf("%d people\n", listed)
+	return nil
+}
+
+func printRefs(role string, refs []helpers.DocumentRef) {
+	for _, ref := range refs {
+		line := fmt.Sprintf("  %-8s %-6s %s", role, ref.Type, ref.ID)
+		if ref.Name != "" {
+			line += " – " + ref.Name
+		}
+		if ref.Dates != "" {
+			line += " (" + ref.Dates + ")"
+		}
+		fmt.Println(line)
+	}
+}
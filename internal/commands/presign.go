@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+)
+
+// runPresign prints pre-signed URLs for keys or published URLs, to share
+// previews of files in a private bucket.
+func runPresign(args []string) error {
+	flags := flag.NewFlagSet("presign", flag.ContinueOnError)
+	expiry := flags.Duration("expiry", time.Duration(helpers.LoadConfig().PresignExpiryMinutes)*time.Minute, "how long the URLs stay valid")
+	if err := flags.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: presign [-expiry 1h] <key or URL>...")
+	}
+
+	for _, arg := range flags.Args() {
+		key, ok := helpers.KeyFromURL(arg)
+		if !ok {
+			key = arg
+		}
+		signed, err := helpers.PresignKey(key, *expiry)
+		if err != nil {
+			return err
+		}
+		fmt.Println(signed)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+)
+
+// runRewriteURLs rebuilds every bucket URL in the output folder from its
+// object key with the configured base URL.
+func runRewriteURLs(args []string) error {
+	flags := flag.NewFlagSet("rewrite-urls", flag.ContinueOnError)
+	from := flags.String("from", "", "comma-separated old base URLs to convert as well, e.g. a previous CDN")
+	dryRun := flags.Bool("dry-run", false, "only report which documents would change")
+	if err := flags.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	var extraBases []string
+	for _, base := range strings.Split(*from, ",") {
+		if base = strings.TrimSpace(base); base != "" {
+			extraBases = append(extraBases, base)
+		}
+	}
+
+	documents, err := helpers.LoadDocuments()
+	if err != nil {
+		return err
+	}
+
+	rewritten := 0
+	for _, document := range documents {
+		changed := helpers.RewriteObjectURLs(document.Data, extraBases)
+		if changed == 0 {
+			continue
+		}
+		rewritten++
+		fmt.Printf("%s: %d fields\n", document.Path, changed)
+		if *dryRun {
+			continue
+		}
+
+		data, err := json.MarshalIndent(document.Data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %v", document.Path, err)
+		}
+		if err := os.WriteFile(document.Path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", document.Path, err)
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("%d of %d documents would be rewritten\n", rewritten, len(documents))
+	} else {
+		fmt.Printf("Rewrote %d of %d documents\n", rewritten, len(documents))
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+)
+
+// runSearch prints the documents in output/ matching a full-text query.
+func runSearch(args []string) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	limit := flags.Int("limit", 20, "maximum number of results, 0 for all")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: search [-limit n] <query>\n\n"+
+			"Words must all match; use \"quoted phrases\", word* prefixes, -word to exclude\n"+
+			"and field:word to search one field (%s).\n\n", strings.Join(helpers.SearchFieldNames(), ", "))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("missing query")
+	}
+
+	results, err := helpers.DocumentIndex().Search(strings.Join(flags.Args(), " "))
+	if err != nil {
+		return err
+	}
+
+	shown := results
+	if *limit > 0 && len(shown) > *limit {
+		shown = shown[:*limit]
+	}
+	for _, result := range shown {
+		title := fmt.Sprintf("%s %s", result.Document.Type, result.Document.ID)
+		if name := result.Document.Name(); name != "" {
+			title += " – " + name
+		}
+		fmt.Printf("%s  %s\n", result.Document.Path, title)
+		if result.Snippet != "" {
+			fmt.Printf("    %s\n", result.Snippet)
+		}
+	}
+	fmt.Printf("%d matching documents\n", len(results))
+	return nil
+}
@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+)
+
+// runTags prints how often each tag is used across the documents.
+func runTags(args []string) error {
+	flags := flag.NewFlagSet("tags", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	tags := helpers.DocumentIndex().Tags()
+	for _, tag := range tags {
+		fmt.Printf("%5d  %s\n", tag.Count, tag.Tag)
+	}
+	fmt.Printf("%d tags\n", len(tags))
+	return nil
+}
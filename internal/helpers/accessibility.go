@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxAltTextLength is the length screen readers comfortably read out in
+// one go.
+const MaxAltTextLength = 125
+
+// redundantAltPrefixes repeat what screen readers already announce.
+var redundantAltPrefixes = []string{"image of", "picture of", "photo of"}
+
+// AccessibilityWarnings checks the alt text of the main image and every
+// sub-image of a document.
+func AccessibilityWarnings(data map[string]interface{}) []string {
+	document, err := genericDocument(data)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var warnings []string
+	if url, _ := document["MainImagePath"].(string); url != "" {
+		altText, _ := document["MainImageAltText"].(string)
+		warnings = append(warnings, checkAltText("Main image", url, altText)...)
+	}
+
+	subImages, _ := document["SubImages"].([]interface{})
+	for i, item := range subImages {
+		subImage, _ := item.(map[string]interface{})
+		url, _ := subImage["URL"].(string)
+		if url == "" {
+			continue
+		}
+		label := fmt.Sprintf("Sub image %d", i+1)
+		if name, _ := subImage["Name"].(string); name != "" {
+			label += fmt.Sprintf(" (%s)", name)
+		}
+		altText, _ := subImage["AltText"].(string)
+		warnings = append(warnings, checkAltText(label, url, altText)...)
+	}
+	return warnings
+}
+
+func checkAltText(label, url, altText string) []string {
+	altText = strings.TrimSpace(altText)
+	if altText == "" {
+		return []string{fmt.Sprintf("%s has no alt text", label)}
+	}
+
+	var warnings []string
+	if length := utf8.RuneCountInString(altText); length > MaxAltTextLength {
+		warnings = append(warnings, fmt.Sprintf("%s alt text is %d characters, keep it under %d", label, length, MaxAltTextLength))
+	}
+	lower := strings.ToLower(altText)
+	for _, prefix := range redundantAltPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			warnings = append(warnings, fmt.Sprintf("%s alt text starts with %q, describe the content instead", label, prefix))
+			break
+		}
+	}
+	if lower == strings.ToLower(path.Base(url)) {
+		warnings = append(warnings, fmt.Sprintf("%s alt text is just the file name", label))
+	}
+	return warnings
+}
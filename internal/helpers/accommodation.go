@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AccommodationTypes are suggested for accommodation records; any other
+// text is accepted.
+var AccommodationTypes = []string{"Hut", "Hotel", "Guesthouse", "Hostel", "Camp", "Bivouac"}
+
+// Accommodation is a place a trip stays at. Cost is the price of the whole
+// stay in Currency.
+type Accommodation struct {
+	Name       string
+	Type       string
+	Address    string
+	Latitude   *float64
+	Longitude  *float64
+	BookingURL string
+	Nights     int
+	Cost       float64
+	Currency   string
+}
+
+// LinkAccommodations matches the accommodation of every itinerary day to a
+// record, correcting the spelling of the name, and fills in the nights of
+// records without any from the days staying there.
+func LinkAccommodations(records []Accommodation, days []ItineraryDay) {
+	nights := make([]int, len(records))
+	for i := range days {
+		for j, record := range records {
+			if days[i].Accommodation != "" && strings.EqualFold(days[i].Accommodation, record.Name) {
+				days[i].Accommodation = record.Name
+				nights[j]++
+				break
+			}
+		}
+	}
+	for i := range records {
+		if records[i].Nights == 0 {
+			records[i].Nights = nights[i]
+		}
+	}
+}
+
+// RenderAccommodationMarkdown renders the records as a numbered markdown
+// list followed by the free-text notes, for consumers that only read the
+// Accommodation text.
+func RenderAccommodationMarkdown(records []Accommodation, notes string) string {
+	var md strings.Builder
+	for i, record := range records {
+		var details []string
+		if record.Type != "" {
+			details = append(details, record.Type)
+		}
+		switch {
+		case record.Nights == 1:
+			details = append(details, "1 night")
+		case record.Nights > 1:
+			details = append(details, fmt.Sprintf("%d nights", record.Nights))
+		}
+		if record.Cost > 0 {
+			details = append(details, FormatAmount(record.Cost, record.Currency))
+		}
+
+		fmt.Fprintf(&md, "%d. **%s**", i+1, record.Name)
+		if len(details) > 0 {
+			fmt.Fprintf(&md, " (%s)", strings.Join(details, ", "))
+		}
+		md.WriteString("\n")
+
+		var lines []string
+		if record.Address != "" {
+			lines = append(lines, strings.Join(strings.Fields(record.Address), " "))
+		}
+		if IsWebURL(record.BookingURL) {
+			lines = append(lines, fmt.Sprintf("[Booking](%s)", record.BookingURL))
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&md, "   %s\n", strings.Join(lines, " · "))
+		}
+	}
+	if notes = strings.TrimSpace(notes); notes != "" {
+		if md.Len() > 0 {
+			md.WriteString("\n")
+		}
+		fmt.Fprintf(&md, "%s\n", notes)
+	}
+	return md.String()
+}
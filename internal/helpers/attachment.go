@@ -0,0 +1,132 @@
+package helpers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// attachmentTypes lists the accepted attachment extensions and the
+// Content-Type each is served with.
+var attachmentTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".webm": "video/webm",
+	".pdf":  "application/pdf",
+	".gpx":  "application/gpx+xml",
+}
+
+// AttachmentExtensions returns the file extensions accepted as attachments.
+func AttachmentExtensions() []string {
+	return []string{".mp4", ".webm", ".pdf", ".gpx"}
+}
+
+// Attachment is a non-image file published with a document.
+type Attachment struct {
+	Name        string
+	FileName    string
+	ContentType string
+	Size        int64
+	URL         string
+}
+
+var unsafeKeyChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// AttachmentKey returns the bucket key for an attachment of the document.
+// A short hash of the content follows the file name, so attachments with
+// the same name from different folders do not overwrite each other.
+func AttachmentKey(prefix, filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	fileName := filepath.Base(filePath)
+	ext := strings.ToLower(filepath.Ext(fileName))
+	stem := unsafeKeyChars.ReplaceAllString(strings.ToLower(strings.TrimSuffix(fileName, filepath.Ext(fileName))), "-")
+	stem = strings.Trim(stem, "-")
+	if stem == "" {
+		stem = "attachment"
+	}
+	return fmt.Sprintf("%s/attachments/%s-%s%s", prefix, stem, hex.EncodeToString(hash.Sum(nil))[:8], ext), nil
+}
+
+// DetectAttachmentType returns the Content-Type of an attachment. The
+// extension decides the type; the file content is sniffed to reject files
+// whose content does not match it.
+func DetectAttachmentType(filePath string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	contentType, ok := attachmentTypes[ext]
+	if !ok {
+		return "", fmt.Errorf("unsupported attachment type %q, use one of %s", ext, strings.Join(AttachmentExtensions(), ", "))
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+
+	switch {
+	case sniffed == contentType:
+	case ext == ".gpx" && (sniffed == "text/xml" || sniffed == "text/plain"):
+	default:
+		return "", fmt.Errorf("%s looks like %s, not %s", filepath.Base(filePath), sniffed, contentType)
+	}
+	return contentType, nil
+}
+
+// UploadAttachment uploads a video, PDF or GPX file. Videos and PDFs are
+// shown inline by browsers; other files are offered as downloads under
+// their original name.
+func UploadAttachment(ctx context.Context, key, filePath string, opts UploadOptions, progress ProgressFunc) (Attachment, error) {
+	contentType, err := DetectAttachmentType(filePath)
+	if err != nil {
+		return Attachment{}, err
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	fileName := filepath.Base(filePath)
+	disposition := "attachment"
+	if strings.HasPrefix(contentType, "video/") || contentType == "application/pdf" {
+		disposition = "inline"
+	}
+
+	opts.ContentType = contentType
+	opts.ContentDisposition = mime.FormatMediaType(disposition, map[string]string{"filename": fileName})
+	opts.OriginalFileName = fileName
+	url, err := UploadToS3(ctx, key, filePath, opts, progress)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	return Attachment{
+		Name:        strings.TrimSuffix(fileName, filepath.Ext(fileName)),
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        info.Size(),
+		URL:         url,
+	}, nil
+}
@@ -0,0 +1,156 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Activities are the kinds of outing events and trips are classified by.
+var Activities = []string{"Hike", "Via Ferrata", "Climbing", "Ski Tour"}
+
+// difficultyGrades lists the usual grading scale of each activity: the SAC
+// hiking scale, the Schall via ferrata scale, UIAA climbing grades and the
+// SAC ski touring scale.
+var difficultyGrades = map[string][]string{
+	"Hike":        {"T1", "T2", "T3", "T4", "T5", "T6"},
+	"Via Ferrata": {"A", "A/B", "B", "B/C", "C", "C/D", "D", "D/E", "E", "F"},
+	"Climbing":    {"III", "IV", "V", "VI", "VII", "VIII", "IX", "X"},
+	"Ski Tour":    {"L", "WS", "ZS", "S", "SS", "AS", "EX"},
+}
+
+// DifficultyGrades returns the grades suggested for an activity.
+func DifficultyGrades(activity string) []string {
+	return difficultyGrades[activity]
+}
+
+// Classification holds the fields the website filters events and trips by.
+type Classification struct {
+	Tags            []string
+	Activity        string
+	Difficulty      string
+	DurationMinutes int
+	DistanceKm      float64
+}
+
+var durationPattern = regexp.MustCompile(`^(?:(\d+(?:[.,]\d+)?)\s*h)?\s*(?:(\d+)\s*(?:m|min))?$`)
+
+// ParseDuration reads durations such as "5h 30m", "5:30", "5.5h" or "330m"
+// into minutes. An empty text is zero.
+func ParseDuration(text string) (int, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return 0, nil
+	}
+	if hours, minutes, ok := strings.Cut(text, ":"); ok {
+		h, errH := strconv.Atoi(hours)
+		m, errM := strconv.Atoi(minutes)
+		if errH == nil && errM == nil && m < 60 {
+			return h*60 + m, nil
+		}
+	} else if match := durationPattern.FindStringSubmatch(text); match != nil && (match[1] != "" || match[2] != "") {
+		total := 0.0
+		if match[1] != "" {
+			hours, _ := strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64)
+			total += hours * 60
+		}
+		if match[2] != "" {
+			minutes, _ := strconv.Atoi(match[2])
+			total += float64(minutes)
+		}
+		return int(math.Round(total)), nil
+	}
+	return 0, fmt.Errorf("invalid duration %q, use e.g. 5h 30m or 5:30", text)
+}
+
+// FormatDuration renders minutes as "5h 30m".
+func FormatDuration(minutes int) string {
+	switch {
+	case minutes <= 0:
+		return ""
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	default:
+		return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+	}
+}
+
+// ParseDistance reads a distance in kilometres, accepting a decimal comma
+// and an optional "km" suffix. An empty text is zero.
+func ParseDistance(text string) (float64, error) {
+	text = strings.TrimSpace(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(text)), "km"))
+	if text == "" {
+		return 0, nil
+	}
+	distance, err := strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64)
+	if err != nil || distance < 0 {
+		return 0, fmt.Errorf("invalid distance %q, enter kilometres", text)
+	}
+	return math.Round(distance*100) / 100, nil
+}
+
+// NormalizeTags trims tags and drops empty and duplicate ones, comparing
+// case-insensitively and keeping the first spelling.
+func NormalizeTags(tags []string) []string {
+	return NormalizeNames(tags)
+}
+
+// NormalizeNames trims names and drops empty and duplicate ones like
+// NormalizeTags.
+func NormalizeNames(names []string) []string {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, name := range names {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// TagCount is how many documents use a tag.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// CountTags aggregates the tags of all documents, most used first.
+func CountTags(documents []Document) []TagCount {
+	counts := make(map[string]*TagCount)
+	for _, document := range documents {
+		tags, _ := document.Data["Tags"].([]interface{})
+		var names []string
+		for _, tag := range tags {
+			if name, ok := tag.(string); ok {
+				names = append(names, name)
+			}
+		}
+		for _, name := range NormalizeTags(names) {
+			key := strings.ToLower(name)
+			if counts[key] == nil {
+				counts[key] = &TagCount{Tag: name}
+			}
+			counts[key].Count++
+		}
+	}
+
+	result := make([]TagCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return strings.ToLower(result[i].Tag) < strings.ToLower(result[j].Tag)
+	})
+	return result
+}
@@ -0,0 +1,232 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Suggested values for the conditions of a report; any other text is
+// accepted.
+var (
+	WeatherTypes    = []string{"Sunny", "Partly cloudy", "Cloudy", "Fog", "Rain", "Thunderstorm", "Snowfall", "Windy"}
+	SnowConditions  = []string{"No snow", "Powder", "Packed", "Wet snow", "Crust", "Ice"}
+	TrailConditions = []string{"Dry", "Wet", "Muddy", "Snow patches", "Snow covered", "Icy", "Overgrown"}
+	HazardTypes     = []string{"Rockfall", "Avalanche risk", "Exposed sections", "Slippery rock", "Fallen trees", "Washed-out path", "River crossing", "Lightning"}
+)
+
+// Conditions describe the weather and terrain met on an outing. The
+// temperatures are in degrees Celsius.
+type Conditions struct {
+	Weather         string
+	TemperatureMinC *float64
+	TemperatureMaxC *float64
+	SnowCondition   string
+	TrailCondition  string
+	Hazards         []string
+}
+
+// IsZero reports whether no condition was entered.
+func (c Conditions) IsZero() bool {
+	return c.Weather == "" && c.TemperatureMinC == nil && c.TemperatureMaxC == nil &&
+		c.SnowCondition == "" && c.TrailCondition == "" && len(c.Hazards) == 0
+}
+
+// ParseTemperature reads a temperature in degrees Celsius, accepting a
+// decimal comma and a "°C" suffix. An empty text is nil.
+func ParseTemperature(text string) (*float64, error) {
+	text = strings.TrimSpace(text)
+	number := strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(text, "C"), "c"), "°"))
+	if number == "" {
+		return nil, nil
+	}
+	temperature, err := strconv.ParseFloat(strings.Replace(number, ",", ".", 1), 64)
+	if err != nil || temperature < -90 || temperature > 60 {
+		return nil, fmt.Errorf("invalid temperature %q, enter degrees Celsius", text)
+	}
+	temperature = math.Round(temperature*10) / 10
+	return &temperature, nil
+}
+
+// weatherColumns lists the header names recognized in weather CSV exports.
+var weatherColumns = map[string][]string{
+	"date":     {"date", "day", "datetime", "time", "timestamp"},
+	"location": {"location", "station", "place", "city", "name"},
+	"weather":  {"weather", "conditions", "condition", "description", "summary"},
+	"min":      {"tmin", "min", "min temp", "temp min", "temperature min", "min temperature", "temperature_min", "temp_min", "mintemp"},
+	"max":      {"tmax", "max", "max temp", "temp max", "temperature max", "max temperature", "temperature_max", "temp_max", "maxtemp"},
+	"temp":     {"temp", "temperature", "temperature_2m", "air temperature"},
+	"snow":     {"snow", "snow condition", "snowcover", "snow depth", "snow_depth"},
+}
+
+// weatherHeader maps each recognized column to its position.
+func weatherHeader(header []string) map[string]int {
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if unit := strings.Index(name, "("); unit > 0 {
+			name = strings.TrimSpace(name[:unit])
+		}
+		for column, aliases := range weatherColumns {
+			if _, ok := columns[column]; ok {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					columns[column] = i
+				}
+			}
+		}
+	}
+	return columns
+}
+
+// readWeatherCSV reads a comma or semicolon separated export.
+func readWeatherCSV(path string) ([][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("%s has no weather rows", path)
+	}
+	return records, nil
+}
+
+// ImportWeather reads the conditions of a day from a weather CSV export.
+// Rows are matched by date and, when the export has a location column, by
+// one of the given location names. Hourly rows of the same day are combined
+// into the temperature range and the most frequent weather.
+func ImportWeather(path, date string, locations []string) (Conditions, error) {
+	day, ok := ParseDate(date)
+	if !ok {
+		return Conditions{}, fmt.Errorf("enter a valid date before importing weather, not %q", date)
+	}
+	records, err := readWeatherCSV(path)
+	if err != nil {
+		return Conditions{}, err
+	}
+	columns := weatherHeader(records[0])
+	if _, ok := columns["date"]; !ok {
+		return Conditions{}, fmt.Errorf("%s has no date column", path)
+	}
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows [][]string
+	available := make(map[string]bool)
+	for _, record := range records[1:] {
+		text := field(record, "date")
+		if len(text) < 8 {
+			continue
+		}
+		// Timestamps such as "2024-07-18T14:00" or "18.07.2024 14:00"
+		rowDate, ok := ParseDate(strings.Fields(strings.Replace(text, "T", " ", 1))[0])
+		if !ok || !rowDate.Equal(day) {
+			continue
+		}
+		if _, ok := columns["location"]; ok && !matchesLocation(field(record, "location"), locations) {
+			available[field(record, "location")] = true
+			continue
+		}
+		rows = append(rows, record)
+	}
+	if len(rows) == 0 {
+		if len(available) > 0 {
+			var names []string
+			for name := range available {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return Conditions{}, fmt.Errorf("no weather for this location on %s, the export has %s", date, strings.Join(names, ", "))
+		}
+		return Conditions{}, fmt.Errorf("no weather for %s in %s", date, path)
+	}
+
+	var conditions Conditions
+	weatherCounts := make(map[string]int)
+	for _, row := range rows {
+		for _, column := range []string{"min", "max", "temp"} {
+			temperature, err := ParseTemperature(field(row, column))
+			if err != nil || temperature == nil {
+				continue
+			}
+			if column != "max" && (conditions.TemperatureMinC == nil || *temperature < *conditions.TemperatureMinC) {
+				conditions.TemperatureMinC = temperature
+			}
+			if column != "min" && (conditions.TemperatureMaxC == nil || *temperature > *conditions.TemperatureMaxC) {
+				conditions.TemperatureMaxC = temperature
+			}
+		}
+		if weather := field(row, "weather"); weather != "" {
+			weatherCounts[weather]++
+		}
+		if snow := field(row, "snow"); snow != "" && conditions.SnowCondition == "" {
+			conditions.SnowCondition = snowCondition(snow)
+		}
+	}
+	for weather, count := range weatherCounts {
+		if count > weatherCounts[conditions.Weather] || (count == weatherCounts[conditions.Weather] && weather < conditions.Weather) {
+			conditions.Weather = weather
+		}
+	}
+	return conditions, nil
+}
+
+// snowCondition describes a snow column, which holds either a text or a
+// snow depth in centimetres.
+func snowCondition(value string) string {
+	depth, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	switch {
+	case err != nil:
+		return value
+	case depth <= 0:
+		return "No snow"
+	default:
+		return fmt.Sprintf("%s cm snow depth", strconv.FormatFloat(depth, 'f', -1, 64))
+	}
+}
+
+// matchesLocation compares a location of the export with the document's
+// location names, ignoring case and allowing either to contain the other.
+func matchesLocation(name string, locations []string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return true
+	}
+	for _, location := range locations {
+		location = strings.ToLower(strings.TrimSpace(location))
+		if location != "" && (strings.Contains(name, location) || strings.Contains(location, name)) {
+			return true
+		}
+	}
+	return false
+}
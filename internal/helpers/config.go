@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+)
+
+const configFile = "config.json"
+
+// Config holds the publisher settings. Every field is optional in
+// config.json; missing values keep their defaults.
+type Config struct {
+	Region            string
+	Bucket            string
+	UploadPartSizeMB  int64
+	UploadConcurrency int
+
+	// PublicBaseURL replaces the S3 endpoint in published URLs, e.g. a
+	// CloudFront distribution in front of a private bucket.
+	PublicBaseURL string
+	// PrivateBucket marks the bucket as not publicly readable; previews in
+	// the publisher then use pre-signed URLs.
+	PrivateBucket        bool
+	PresignExpiryMinutes int
+
+	// CacheControl is sent with every upload. Keys such as main.webp are
+	// overwritten when an image is replaced, so keep max-age moderate.
+	CacheControl string
+	// Uploader is stored in the metadata of uploaded objects; it defaults
+	// to the login name.
+	Uploader string
+	// Author is prefilled as the author of new documents.
+	Author string
+}
+
+var (
+	config     Config
+	configOnce sync.Once
+)
+
+// LoadConfig reads config.json from the working directory once and returns
+// the merged settings.
+func LoadConfig() Config {
+	configOnce.Do(func() {
+		config = Config{
+			Region:            "us-east-1",
+			Bucket:            "hikes-trailfinder-website-images",
+			UploadPartSizeMB:  8,
+			UploadConcurrency: 4,
+
+			PresignExpiryMinutes: 60,
+
+			CacheControl: "public, max-age=86400",
+		}
+		if current, err := user.Current(); err == nil {
+			config.Uploader = current.Username
+		}
+
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.Printf("failed to read %s: %v", configFile, err)
+			}
+			return
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			log.Printf("failed to parse %s: %v", configFile, err)
+		}
+		config.PublicBaseURL = strings.TrimSuffix(config.PublicBaseURL, "/")
+		if config.PrivateBucket && config.PublicBaseURL == "" {
+			log.Printf("%s: PrivateBucket is set without PublicBaseURL, published URLs will not be readable", configFile)
+		}
+	})
+	return config
+}
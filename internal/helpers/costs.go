@@ -0,0 +1,189 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const ratesFile = "rates.json"
+
+// CostCategories are suggested for cost items; any other text is accepted.
+var CostCategories = []string{"Transport", "Accommodation", "Food", "Fees", "Gear", "Guide", "Other"}
+
+// ExchangeRates converts between currencies. Rates holds the value of one
+// unit of each currency in the base currency.
+type ExchangeRates struct {
+	Base  string
+	Rates map[string]float64
+}
+
+// LoadExchangeRates reads rates.json from the working directory. Without
+// the file only amounts in a single currency can be added up.
+func LoadExchangeRates() (ExchangeRates, error) {
+	rates := ExchangeRates{Base: "EUR", Rates: map[string]float64{}}
+	data, err := os.ReadFile(ratesFile)
+	if os.IsNotExist(err) {
+		return rates, nil
+	}
+	if err != nil {
+		return rates, fmt.Errorf("failed to read %s: %v", ratesFile, err)
+	}
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return ExchangeRates{Base: "EUR", Rates: map[string]float64{}}, fmt.Errorf("failed to parse %s: %v", ratesFile, err)
+	}
+	rates.Base = strings.ToUpper(rates.Base)
+	normalized := make(map[string]float64, len(rates.Rates))
+	for currency, rate := range rates.Rates {
+		normalized[strings.ToUpper(currency)] = rate
+	}
+	rates.Rates = normalized
+	return rates, nil
+}
+
+// Currencies returns the base currency followed by all others in rates.json.
+func (r ExchangeRates) Currencies() []string {
+	currencies := []string{r.Base}
+	var others []string
+	for currency := range r.Rates {
+		if currency != r.Base {
+			others = append(others, currency)
+		}
+	}
+	sort.Strings(others)
+	return append(currencies, others...)
+}
+
+func (r ExchangeRates) rate(currency string) (float64, bool) {
+	if currency == r.Base {
+		return 1, true
+	}
+	rate, ok := r.Rates[currency]
+	return rate, ok && rate > 0
+}
+
+// Convert changes an amount from one currency to another.
+func (r ExchangeRates) Convert(amount float64, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return amount, nil
+	}
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s in %s", from, ratesFile)
+	}
+	toRate, ok := r.rate(to)
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s in %s", to, ratesFile)
+	}
+	return amount * fromRate / toRate, nil
+}
+
+// CostItem is one line of a cost breakdown. PerPerson amounts are paid by
+// every participant; the others are shared by the group.
+type CostItem struct {
+	Category    string
+	Description string
+	Amount      float64
+	Currency    string
+	PerPerson   bool
+}
+
+// CostBreakdown is the structured cost table of an event or trip. Total and
+// PerPerson are in Currency and are filled in by Calculate.
+type CostBreakdown struct {
+	Items        []CostItem
+	Currency     string
+	Participants int
+	Total        float64
+	PerPerson    float64
+}
+
+// Calculate converts every item to the breakdown currency and fills in the
+// group total and the cost per participant.
+func (b *CostBreakdown) Calculate(rates ExchangeRates) error {
+	participants := max(b.Participants, 1)
+	var shared, perPerson float64
+	for _, item := range b.Items {
+		amount, err := rates.Convert(item.Amount, item.Currency, b.Currency)
+		if err != nil {
+			return err
+		}
+		if item.PerPerson {
+			perPerson += amount
+		} else {
+			shared += amount
+		}
+	}
+	b.Total = roundCents(shared + perPerson*float64(participants))
+	b.PerPerson = roundCents(shared/float64(participants) + perPerson)
+	return nil
+}
+
+// ParseAmount reads a money amount, accepting a decimal comma.
+func ParseAmount(text string) (float64, error) {
+	text = strings.TrimSpace(text)
+	amount, err := strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("invalid amount %q", text)
+	}
+	return roundCents(amount), nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// FormatAmount renders an amount as "1,234.50 EUR".
+func FormatAmount(amount float64, currency string) string {
+	text := fmt.Sprintf("%.2f", math.Abs(amount))
+	whole, cents, _ := strings.Cut(text, ".")
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s%s.%s %s", sign, grouped.String(), cents, currency))
+}
+
+// RenderCostsMarkdown renders the breakdown as a markdown table with
+// totals followed by the free-text notes, for consumers that only read the
+// Costs text.
+func RenderCostsMarkdown(b CostBreakdown, notes string) string {
+	var md strings.Builder
+	md.WriteString("| Category | Description | Amount | Per person |\n")
+	md.WriteString("| --- | --- | ---: | :---: |\n")
+	for _, item := range b.Items {
+		perPerson := ""
+		if item.PerPerson {
+			perPerson = "✓"
+		}
+		fmt.Fprintf(&md, "| %s | %s | %s | %s |\n",
+			escapeTableCell(item.Category), escapeTableCell(item.Description), FormatAmount(item.Amount, item.Currency), perPerson)
+	}
+
+	people := "person"
+	if b.Participants != 1 {
+		people = "people"
+	}
+	fmt.Fprintf(&md, "\n**Total:** %s for %d %s  \n", FormatAmount(b.Total, b.Currency), max(b.Participants, 1), people)
+	fmt.Fprintf(&md, "**Per person:** %s\n", FormatAmount(b.PerPerson, b.Currency))
+	if notes = strings.TrimSpace(notes); notes != "" {
+		fmt.Fprintf(&md, "\n%s\n", notes)
+	}
+	return md.String()
+}
+
+func escapeTableCell(text string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(text), " "), "|", `\|`)
+}
@@ -0,0 +1,159 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const uploadCacheFile = ".upload-cache.json"
+
+// uploadCache remembers which object key holds the content with a given
+// SHA-256, so re-uploading the same photo can copy the existing object
+// within the bucket instead of sending it again.
+type uploadCache struct {
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]string
+}
+
+var uploadHashes = &uploadCache{}
+
+func (c *uploadCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = make(map[string]string)
+	data, err := os.ReadFile(uploadCacheFile)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		log.Printf("failed to parse %s: %v", uploadCacheFile, err)
+	}
+}
+
+func (c *uploadCache) get(hash string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	key, ok := c.entries[hash]
+	return key, ok
+}
+
+func (c *uploadCache) set(hash, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	if key == "" {
+		delete(c.entries, hash)
+	} else {
+		c.entries[hash] = key
+	}
+	c.save()
+}
+
+// rename points every hash stored under oldKey to newKey after a move.
+func (c *uploadCache) rename(oldKey, newKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	for hash, key := range c.entries {
+		if key == oldKey {
+			c.entries[hash] = newKey
+		}
+	}
+	c.save()
+}
+
+// forget drops every hash stored under one of the deleted keys.
+func (c *uploadCache) forget(keys ...string) {
+	deleted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		deleted[key] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	for hash, key := range c.entries {
+		if deleted[key] {
+			delete(c.entries, hash)
+		}
+	}
+	c.save()
+}
+
+func (c *uploadCache) save() {
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err == nil {
+		err = os.WriteFile(uploadCacheFile, data, 0644)
+	}
+	if err != nil {
+		log.Printf("failed to write %s: %v", uploadCacheFile, err)
+	}
+}
+
+// findExistingObject returns the key of an object that already stores the
+// content, checking the cached key first and then the upload target itself.
+// Candidates are only trusted when their stored hash and size still match.
+func findExistingObject(ctx context.Context, svc *s3.S3, bucket string, sums fileChecksums, targetKey string) (string, bool) {
+	candidates := []string{targetKey}
+	if cachedKey, ok := uploadHashes.get(sums.sha256); ok && cachedKey != targetKey {
+		candidates = append([]string{cachedKey}, candidates...)
+	}
+
+	for _, key := range candidates {
+		head, err := svc.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err == nil && aws.StringValue(head.Metadata[sha256MetadataKey]) == sums.sha256 && aws.Int64Value(head.ContentLength) == sums.size {
+			uploadHashes.set(sums.sha256, key)
+			return key, true
+		}
+		if cachedKey, _ := uploadHashes.get(sums.sha256); cachedKey == key {
+			uploadHashes.set(sums.sha256, "")
+		}
+	}
+	return "", false
+}
+
+// copyExistingObject copies an object holding the same content to the
+// upload's own key, storing the headers, metadata and tags of the upload.
+// The source may belong to another document or a draft, so the copy keeps
+// this document independent of whatever later happens to it.
+func copyExistingObject(ctx context.Context, svc *s3.S3, cfg Config, srcKey, dstKey string, opts UploadOptions, hash string) error {
+	var contentDisposition, cacheControl *string
+	if opts.ContentDisposition != "" {
+		contentDisposition = aws.String(opts.ContentDisposition)
+	}
+	if cfg.CacheControl != "" {
+		cacheControl = aws.String(cfg.CacheControl)
+	}
+
+	_, err := svc.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+		Bucket:             aws.String(cfg.Bucket),
+		Key:                aws.String(dstKey),
+		CopySource:         aws.String(escapeKey(cfg.Bucket + "/" + srcKey)),
+		MetadataDirective:  aws.String(s3.MetadataDirectiveReplace),
+		ContentType:        aws.String(opts.ContentType),
+		ContentDisposition: contentDisposition,
+		CacheControl:       cacheControl,
+		Metadata:           opts.metadata(cfg, hash),
+		TaggingDirective:   aws.String(s3.TaggingDirectiveReplace),
+		Tagging:            objectTags(opts.DocumentID, opts.DocumentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %v", srcKey, dstKey, err)
+	}
+	uploadHashes.set(hash, dstKey)
+	return nil
+}
@@ -0,0 +1,191 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// OutputFolder is where published documents are written.
+const OutputFolder = "output"
+
+// documentTypes maps the JSON key holding a document's unique ID to the
+// type of document.
+var documentTypes = []struct {
+	idField, nameField, dateField, endDateField, docType string
+}{
+	{"UniqueReportID", "ReportName", "ReportDate", "", "Report"},
+	{"UniqueEventID", "EventName", "EventDate", "", "Event"},
+	{"UniqueTripID", "TripName", "TripStartDate", "TripEndDate", "Trip"},
+}
+
+// dateLayouts are the date formats found in published documents.
+var dateLayouts = []string{"2006-01-02", "02-01-2006", "02.01.2006", "02/01/2006"}
+
+// Document is a published JSON document read back from the output folder.
+type Document struct {
+	Path string
+	ID   string
+	Type string
+	Data map[string]interface{}
+}
+
+// LoadDocuments reads every JSON document below the output folder. Files
+// without a unique ID are skipped. Files that cannot be read do not stop
+// the others from loading; their errors are returned together with the
+// documents that could be read.
+func LoadDocuments() ([]Document, error) {
+	var documents []Document
+	var errs []error
+	err := filepath.WalkDir(OutputFolder, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == OutputFolder {
+				return err
+			}
+			errs = append(errs, err)
+			return nil
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		document, ok, err := LoadDocument(path)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ok {
+			documents = append(documents, document)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return documents, errors.Join(errs...)
+}
+
+// LoadDocument reads one JSON file. ok is false for files without a
+// unique ID.
+func LoadDocument(path string) (document Document, ok bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Document{}, false, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return Document{}, false, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	for _, documentType := range documentTypes {
+		if id, ok := fields[documentType.idField].(string); ok && strings.TrimSpace(id) != "" {
+			return Document{Path: path, ID: strings.TrimSpace(id), Type: documentType.docType, Data: fields}, true, nil
+		}
+	}
+	return Document{}, false, nil
+}
+
+// ReferencedKeys returns the bucket keys the document refers to: the keys
+// of URLs under the configured bases and the keys stored next to URL
+// fields. Unrecognized lists object URLs whose base is not configured, such
+// as links to a previous CDN, whose objects may be referenced without the
+// key being known.
+func (d Document) ReferencedKeys() (keys []string, unrecognized []string) {
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case string:
+			if key, ok := KeyFromURL(v); ok {
+				keys = append(keys, key)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]interface{}:
+			for field, item := range v {
+				walk(item)
+				text, ok := item.(string)
+				if !ok {
+					continue
+				}
+				storedKey, _ := v[ObjectKeyField(field)].(string)
+				if storedKey != "" {
+					keys = append(keys, storedKey)
+				}
+				if _, ok := KeyFromURL(text); !ok && (storedKey != "" || d.looksLikeObjectURL(text)) {
+					unrecognized = append(unrecognized, text)
+				}
+			}
+		}
+	}
+	walk(d.Data)
+	return keys, unrecognized
+}
+
+// looksLikeObjectURL reports whether a URL points below the document's own
+// or a draft prefix on some host, as uploads do.
+func (d Document) looksLikeObjectURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+	return strings.Contains(parsed.Path, "/"+d.ID+"/") || strings.Contains(parsed.Path, "/"+DraftPrefix+"/")
+}
+
+// Text returns a string field of the document, or "" if it is missing.
+func (d Document) Text(field string) string {
+	text, _ := d.Data[field].(string)
+	return text
+}
+
+// Name returns the report, event or trip name.
+func (d Document) Name() string {
+	for _, documentType := range documentTypes {
+		if documentType.docType == d.Type {
+			return d.Text(documentType.nameField)
+		}
+	}
+	return ""
+}
+
+// Dates returns the document date, or the date range of a trip.
+func (d Document) Dates() string {
+	for _, documentType := range documentTypes {
+		if documentType.docType != d.Type {
+			continue
+		}
+		start := d.Text(documentType.dateField)
+		if end := d.Text(documentType.endDateField); end != "" && end != start {
+			return fmt.Sprintf("%s – %s", start, end)
+		}
+		return start
+	}
+	return ""
+}
+
+// Date returns the parsed document date or trip start date.
+func (d Document) Date() (time.Time, bool) {
+	for _, documentType := range documentTypes {
+		if documentType.docType == d.Type {
+			return ParseDate(d.Text(documentType.dateField))
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParseDate parses a date in any of the formats used in documents.
+func ParseDate(text string) (time.Time, bool) {
+	text = strings.TrimSpace(text)
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, text); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,197 @@
+package helpers
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	profileWidth        = 1200
+	profileHeight       = 400
+	profileMarginLeft   = 70
+	profileMarginRight  = 20
+	profileMarginTop    = 20
+	profileMarginBottom = 40
+)
+
+var (
+	profileBackground = color.RGBA{255, 255, 255, 255}
+	profileGrid       = color.RGBA{225, 225, 225, 255}
+	profileAxis       = color.RGBA{90, 90, 90, 255}
+	profileFill       = color.RGBA{140, 190, 140, 255}
+	profileLine       = color.RGBA{40, 110, 40, 255}
+)
+
+// RenderElevationProfile draws a distance vs. altitude chart of the track and
+// writes it as a PNG, since the standard library has no WebP encoder.
+func RenderElevationProfile(points []TrackPoint, outputPath string) error {
+	if len(points) < 2 {
+		return fmt.Errorf("not enough track points for an elevation profile")
+	}
+
+	distances := CumulativeDistances(points)
+	totalKm := distances[len(distances)-1]
+	if totalKm == 0 {
+		return fmt.Errorf("track has no distance")
+	}
+
+	minEle, maxEle := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		minEle = math.Min(minEle, p.Ele)
+		maxEle = math.Max(maxEle, p.Ele)
+	}
+	eleStep := niceStep(maxEle-minEle, 5)
+	minEle = math.Floor(minEle/eleStep) * eleStep
+	maxEle = math.Ceil(maxEle/eleStep) * eleStep
+	if maxEle == minEle {
+		maxEle = minEle + eleStep
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, profileWidth, profileHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{profileBackground}, image.Point{}, draw.Src)
+
+	plotW := float64(profileWidth - profileMarginLeft - profileMarginRight)
+	plotH := float64(profileHeight - profileMarginTop - profileMarginBottom)
+	toX := func(km float64) int {
+		return profileMarginLeft + int(km/totalKm*plotW)
+	}
+	toY := func(ele float64) int {
+		return profileMarginTop + int((maxEle-ele)/(maxEle-minEle)*plotH)
+	}
+	baseY := toY(minEle)
+
+	for i := 0; minEle+float64(i)*eleStep <= maxEle; i++ {
+		ele := minEle + float64(i)*eleStep
+		y := toY(ele)
+		drawHLine(img, profileMarginLeft, profileWidth-profileMarginRight, y, profileGrid)
+		drawLabel(img, 5, y+4, fmt.Sprintf("%.0f m", ele))
+	}
+	kmStep := niceStep(totalKm, 10)
+	for i := 0; float64(i)*kmStep <= totalKm; i++ {
+		km := float64(i) * kmStep
+		x := toX(km)
+		drawVLine(img, x, profileMarginTop, baseY, profileGrid)
+		drawLabel(img, x-10, profileHeight-profileMarginBottom+20, fmt.Sprintf("%s km", strconv.FormatFloat(km, 'f', -1, 64)))
+	}
+
+	// Fill each pixel column up to the interpolated altitude, then trace the line on top.
+	for x := profileMarginLeft; x <= profileWidth-profileMarginRight; x++ {
+		km := float64(x-profileMarginLeft) / plotW * totalKm
+		y := toY(elevationAt(distances, points, km))
+		drawVLine(img, x, y, baseY, profileFill)
+	}
+	for i := 1; i < len(points); i++ {
+		drawLine(img, toX(distances[i-1]), toY(points[i-1].Ele), toX(distances[i]), toY(points[i].Ele), profileLine)
+	}
+
+	drawHLine(img, profileMarginLeft, profileWidth-profileMarginRight, baseY, profileAxis)
+	drawVLine(img, profileMarginLeft, profileMarginTop, baseY, profileAxis)
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create elevation profile: %v", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("failed to encode elevation profile: %v", err)
+	}
+	return nil
+}
+
+func elevationAt(distances []float64, points []TrackPoint, km float64) float64 {
+	for i := 1; i < len(distances); i++ {
+		if distances[i] >= km {
+			span := distances[i] - distances[i-1]
+			if span == 0 {
+				return points[i].Ele
+			}
+			t := (km - distances[i-1]) / span
+			return points[i-1].Ele + t*(points[i].Ele-points[i-1].Ele)
+		}
+	}
+	return points[len(points)-1].Ele
+}
+
+// niceStep picks a 1/2/5 multiple of a power of ten giving roughly the
+// requested number of grid lines over the span.
+func niceStep(span float64, lines int) float64 {
+	if span <= 0 {
+		return 1
+	}
+	raw := span / float64(lines)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, factor := range []float64{1, 2, 5, 10} {
+		if raw <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func drawHLine(img *image.RGBA, x1, x2, y int, c color.Color) {
+	for x := x1; x <= x2; x++ {
+		img.Set(x, y, c)
+	}
+}
+
+func drawVLine(img *image.RGBA, x, y1, y2 int, c color.Color) {
+	for y := y1; y <= y2; y++ {
+		img.Set(x, y, c)
+	}
+}
+
+func drawLine(img *image.RGBA, x1, y1, x2, y2 int, c color.Color) {
+	dx, dy := abs(x2-x1), -abs(y2-y1)
+	sx, sy := 1, 1
+	if x1 > x2 {
+		sx = -1
+	}
+	if y1 > y2 {
+		sy = -1
+	}
+	errAcc := dx + dy
+	for {
+		img.Set(x1, y1, c)
+		img.Set(x1, y1+1, c)
+		if x1 == x2 && y1 == y2 {
+			return
+		}
+		e2 := 2 * errAcc
+		if e2 >= dy {
+			errAcc += dy
+			x1 += sx
+		}
+		if e2 <= dx {
+			errAcc += dx
+			y1 += sy
+		}
+	}
+}
+
+func drawLabel(img *image.RGBA, x, y int, text string) {
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(profileAxis),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(text)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
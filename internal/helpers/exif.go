@@ -0,0 +1,326 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	tagImageDescription = 0x010E
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagXPTitle          = 0x9C9B
+	tagExifIFD          = 0x8769
+	tagGPSInfo          = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagGPSLatRef        = 0x0001
+	tagGPSLatitude      = 0x0002
+	tagGPSLongRef       = 0x0003
+	tagGPSLongitude     = 0x0004
+)
+
+// sensitiveTags identify the photographer or their camera and are blanked
+// before upload, together with the whole GPS directory.
+var sensitiveTags = map[uint16]bool{
+	0x013B: true, // Artist
+	0x927C: true, // MakerNote, which often embeds serial numbers
+	0xA430: true, // CameraOwnerName
+	0xA431: true, // BodySerialNumber
+	0xA435: true, // LensSerialNumber
+	0xC62F: true, // CameraSerialNumber
+}
+
+type ImageMetadata struct {
+	CaptureTime time.Time
+	CameraMake  string
+	CameraModel string
+	Orientation int
+	Title       string
+	Latitude    *float64
+	Longitude   *float64
+}
+
+// Camera returns the make and model as a single display name.
+func (m ImageMetadata) Camera() string {
+	if strings.HasPrefix(strings.ToLower(m.CameraModel), strings.ToLower(m.CameraMake)) {
+		return m.CameraModel
+	}
+	return strings.TrimSpace(m.CameraMake + " " + m.CameraModel)
+}
+
+type exifEntry struct {
+	tag    uint16
+	typ    uint16
+	count  uint32
+	offset int // position of the value bytes inside the TIFF block
+}
+
+type exifReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// ReadImageMetadata extracts EXIF metadata from a JPEG, WebP or PNG file.
+// Images without an EXIF block yield empty metadata and no error.
+func ReadImageMetadata(filePath string) (ImageMetadata, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return ImageMetadata{}, fmt.Errorf("failed to read image: %v", err)
+	}
+	return parseImageMetadata(data)
+}
+
+func parseImageMetadata(data []byte) (ImageMetadata, error) {
+	return parseExif(findExifBlock(data))
+}
+
+// parseExif reads the metadata of an EXIF TIFF block, which may be nil.
+func parseExif(tiff []byte) (ImageMetadata, error) {
+	metadata := ImageMetadata{Orientation: 1}
+	if tiff == nil {
+		return metadata, nil
+	}
+	reader, err := newExifReader(tiff)
+	if err != nil {
+		return metadata, err
+	}
+
+	ifd0, err := reader.readIFD(reader.firstIFD())
+	if err != nil {
+		return metadata, err
+	}
+	metadata.CameraMake = reader.ascii(ifd0[tagMake])
+	metadata.CameraModel = reader.ascii(ifd0[tagModel])
+	metadata.Title = reader.ascii(ifd0[tagImageDescription])
+	if title := reader.utf16(ifd0[tagXPTitle]); title != "" {
+		metadata.Title = title
+	}
+	if orientation, ok := ifd0[tagOrientation]; ok && orientation.typ == 3 {
+		metadata.Orientation = int(reader.order.Uint16(reader.data[orientation.offset:]))
+	}
+
+	if exifPointer, ok := ifd0[tagExifIFD]; ok {
+		exif, err := reader.readIFD(int(reader.uint32At(exifPointer.offset)))
+		if err != nil {
+			return metadata, err
+		}
+		if captured, err := time.Parse("2006:01:02 15:04:05", reader.ascii(exif[tagDateTimeOriginal])); err == nil {
+			metadata.CaptureTime = captured
+		}
+	}
+	if gpsPointer, ok := ifd0[tagGPSInfo]; ok {
+		gps, err := reader.readIFD(int(reader.uint32At(gpsPointer.offset)))
+		if err != nil {
+			return metadata, err
+		}
+		metadata.Latitude = reader.gpsCoordinate(gps[tagGPSLatitude], gps[tagGPSLatRef], "S")
+		metadata.Longitude = reader.gpsCoordinate(gps[tagGPSLongitude], gps[tagGPSLongRef], "W")
+	}
+	return metadata, nil
+}
+
+// findExifBlock returns the TIFF structure holding the EXIF data of a JPEG
+// (APP1 segment), WebP (EXIF chunk) or PNG (eXIf chunk) file.
+func findExifBlock(data []byte) []byte {
+	switch {
+	case len(data) > 4 && data[0] == 0xFF && data[1] == 0xD8:
+		for pos := 2; pos+4 <= len(data); {
+			if data[pos] != 0xFF {
+				return nil
+			}
+			marker := data[pos+1]
+			if marker == 0xDA || marker == 0xD9 {
+				return nil
+			}
+			length := int(binary.BigEndian.Uint16(data[pos+2:]))
+			end := pos + 2 + length
+			if end > len(data) {
+				return nil
+			}
+			segment := data[pos+4 : end]
+			if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+				return segment[6:]
+			}
+			pos = end
+		}
+	case len(data) > 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		for pos := 12; pos+8 <= len(data); {
+			size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+			end := pos + 8 + size
+			if end > len(data) {
+				return nil
+			}
+			if string(data[pos:pos+4]) == "EXIF" {
+				return bytes.TrimPrefix(data[pos+8:end], []byte("Exif\x00\x00"))
+			}
+			pos = end + size%2
+		}
+	case len(data) > 8 && string(data[1:4]) == "PNG":
+		for pos := 8; pos+8 <= len(data); {
+			size := int(binary.BigEndian.Uint32(data[pos:]))
+			end := pos + 8 + size
+			if end > len(data) {
+				return nil
+			}
+			if string(data[pos+4:pos+8]) == "eXIf" {
+				return data[pos+8 : end]
+			}
+			pos = end + 4
+		}
+	}
+	return nil
+}
+
+func newExifReader(tiff []byte) (*exifReader, error) {
+	if len(tiff) < 8 {
+		return nil, fmt.Errorf("EXIF block too short")
+	}
+	switch string(tiff[0:2]) {
+	case "II":
+		return &exifReader{data: tiff, order: binary.LittleEndian}, nil
+	case "MM":
+		return &exifReader{data: tiff, order: binary.BigEndian}, nil
+	}
+	return nil, fmt.Errorf("invalid EXIF byte order")
+}
+
+func (r *exifReader) firstIFD() int {
+	return int(r.order.Uint32(r.data[4:]))
+}
+
+func (r *exifReader) uint32At(pos int) uint32 {
+	return r.order.Uint32(r.data[pos:])
+}
+
+func (r *exifReader) readIFD(offset int) (map[uint16]exifEntry, error) {
+	if offset <= 0 || offset+2 > len(r.data) {
+		return nil, fmt.Errorf("invalid EXIF directory offset")
+	}
+	count := int(r.order.Uint16(r.data[offset:]))
+	entries := make(map[uint16]exifEntry, count)
+	for i := 0; i < count; i++ {
+		pos := offset + 2 + i*12
+		if pos+12 > len(r.data) {
+			return nil, fmt.Errorf("truncated EXIF directory")
+		}
+		entry := exifEntry{
+			tag:    r.order.Uint16(r.data[pos:]),
+			typ:    r.order.Uint16(r.data[pos+2:]),
+			count:  r.order.Uint32(r.data[pos+4:]),
+			offset: pos + 8,
+		}
+		// Values that do not fit in the four byte field are stored elsewhere.
+		if exifTypeSize(entry.typ)*int(entry.count) > 4 {
+			entry.offset = int(r.order.Uint32(r.data[pos+8:]))
+		}
+		if entry.offset+exifTypeSize(entry.typ)*int(entry.count) > len(r.data) {
+			continue
+		}
+		entries[entry.tag] = entry
+	}
+	return entries, nil
+}
+
+func (r *exifReader) ascii(e exifEntry) string {
+	if e.count == 0 {
+		return ""
+	}
+	return string(bytes.TrimRight(r.data[e.offset:e.offset+int(e.count)], "\x00 "))
+}
+
+func (r *exifReader) utf16(e exifEntry) string {
+	raw := r.data[e.offset : e.offset+int(e.count)]
+	units := make([]uint16, 0, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		units = append(units, binary.LittleEndian.Uint16(raw[i:]))
+	}
+	return strings.TrimRight(string(utf16.Decode(units)), "\x00 ")
+}
+
+func (r *exifReader) rationals(e exifEntry) []float64 {
+	var values []float64
+	for i := 0; i < int(e.count); i++ {
+		num := float64(r.order.Uint32(r.data[e.offset+i*8:]))
+		den := float64(r.order.Uint32(r.data[e.offset+i*8+4:]))
+		if den == 0 {
+			values = append(values, 0)
+			continue
+		}
+		values = append(values, num/den)
+	}
+	return values
+}
+
+func (r *exifReader) gpsCoordinate(value, ref exifEntry, negativeRef string) *float64 {
+	if value.count != 3 || value.typ != 5 {
+		return nil
+	}
+	dms := r.rationals(value)
+	coordinate := dms[0] + dms[1]/60 + dms[2]/3600
+	if r.ascii(ref) == negativeRef {
+		coordinate = -coordinate
+	}
+	return &coordinate
+}
+
+// scrub blanks the GPS directory and every sensitive tag in place, leaving
+// capture time, camera model and orientation intact.
+func (r *exifReader) scrub() error {
+	offsets := []int{r.firstIFD()}
+	ifd0, err := r.readIFD(offsets[0])
+	if err != nil {
+		return err
+	}
+	if exifPointer, ok := ifd0[tagExifIFD]; ok {
+		offsets = append(offsets, int(r.uint32At(exifPointer.offset)))
+	}
+	for _, offset := range offsets {
+		entries, err := r.readIFD(offset)
+		if err != nil {
+			return err
+		}
+		for tag, entry := range entries {
+			if sensitiveTags[tag] {
+				r.zero(entry)
+			}
+		}
+	}
+
+	if gpsPointer, ok := ifd0[tagGPSInfo]; ok {
+		gpsOffset := int(r.uint32At(gpsPointer.offset))
+		gps, err := r.readIFD(gpsOffset)
+		if err != nil {
+			return err
+		}
+		for _, entry := range gps {
+			r.zero(entry)
+		}
+		// An empty directory keeps the pointer from IFD0 valid.
+		r.order.PutUint16(r.data[gpsOffset:], 0)
+	}
+	return nil
+}
+
+func (r *exifReader) zero(e exifEntry) {
+	clear(r.data[e.offset : e.offset+exifTypeSize(e.typ)*int(e.count)])
+}
+
+func exifTypeSize(typ uint16) int {
+	switch typ {
+	case 1, 2, 6, 7:
+		return 1
+	case 3, 8:
+		return 2
+	case 4, 9, 11:
+		return 4
+	case 5, 10, 12:
+		return 8
+	}
+	return 1
+}
@@ -0,0 +1,181 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const gearTemplatesFile = "gear-templates.json"
+
+// GearItem is one entry of a packing list.
+type GearItem struct {
+	Name        string
+	WeightGrams int
+	Optional    bool
+}
+
+// GearTemplate is a named packing list to start documents from.
+type GearTemplate struct {
+	Name  string
+	Items []GearItem
+}
+
+// defaultGearTemplates are offered until templates are saved locally.
+var defaultGearTemplates = []GearTemplate{
+	{Name: "Summer Hike", Items: []GearItem{
+		{Name: "Backpack 30 l", WeightGrams: 1100},
+		{Name: "Rain jacket", WeightGrams: 350},
+		{Name: "Fleece", WeightGrams: 300},
+		{Name: "Water bottle 1 l", WeightGrams: 150},
+		{Name: "First aid kit", WeightGrams: 200},
+		{Name: "Headlamp", WeightGrams: 80},
+		{Name: "Sun cap and sunglasses", WeightGrams: 120},
+		{Name: "Sunscreen", WeightGrams: 100},
+		{Name: "Trekking poles", WeightGrams: 500, Optional: true},
+	}},
+	{Name: "Winter Hike", Items: []GearItem{
+		{Name: "Backpack 35 l", WeightGrams: 1300},
+		{Name: "Hardshell jacket", WeightGrams: 450},
+		{Name: "Down jacket", WeightGrams: 400},
+		{Name: "Gloves and hat", WeightGrams: 200},
+		{Name: "Thermos 1 l", WeightGrams: 450},
+		{Name: "First aid kit", WeightGrams: 200},
+		{Name: "Headlamp", WeightGrams: 80},
+		{Name: "Microspikes", WeightGrams: 400},
+		{Name: "Avalanche transceiver", WeightGrams: 250, Optional: true},
+		{Name: "Snowshoes", WeightGrams: 1800, Optional: true},
+	}},
+	{Name: "Via Ferrata", Items: []GearItem{
+		{Name: "Helmet", WeightGrams: 350},
+		{Name: "Harness", WeightGrams: 400},
+		{Name: "Via ferrata lanyard", WeightGrams: 500},
+		{Name: "Gloves", WeightGrams: 100},
+		{Name: "Backpack 25 l", WeightGrams: 900},
+		{Name: "Rain jacket", WeightGrams: 350},
+		{Name: "First aid kit", WeightGrams: 200},
+		{Name: "Sling and carabiner", WeightGrams: 150, Optional: true},
+	}},
+}
+
+// LoadGearTemplates reads gear-templates.json from the working directory,
+// falling back to the built-in templates.
+func LoadGearTemplates() ([]GearTemplate, error) {
+	data, err := os.ReadFile(gearTemplatesFile)
+	if os.IsNotExist(err) {
+		return append([]GearTemplate{}, defaultGearTemplates...), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", gearTemplatesFile, err)
+	}
+	var templates []GearTemplate
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", gearTemplatesFile, err)
+	}
+	return templates, nil
+}
+
+// SaveGearTemplate adds a template to gear-templates.json, replacing one
+// with the same name.
+func SaveGearTemplate(template GearTemplate) error {
+	templates, err := LoadGearTemplates()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i, existing := range templates {
+		if strings.EqualFold(existing.Name, template.Name) {
+			templates[i] = template
+			replaced = true
+		}
+	}
+	if !replaced {
+		templates = append(templates, template)
+	}
+
+	data, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode gear templates: %v", err)
+	}
+	if err := os.WriteFile(gearTemplatesFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", gearTemplatesFile, err)
+	}
+	return nil
+}
+
+// GearList is the packing list of an event or trip. The weights are
+// filled in by Calculate; RequiredWeightGrams leaves out optional items.
+type GearList struct {
+	Template            string
+	Items               []GearItem
+	TotalWeightGrams    int
+	RequiredWeightGrams int
+}
+
+// Calculate adds up the pack weight.
+func (l *GearList) Calculate() {
+	l.TotalWeightGrams, l.RequiredWeightGrams = 0, 0
+	for _, item := range l.Items {
+		l.TotalWeightGrams += item.WeightGrams
+		if !item.Optional {
+			l.RequiredWeightGrams += item.WeightGrams
+		}
+	}
+}
+
+// ParseWeight reads weights such as "350", "350 g" or "1,2 kg" into grams.
+// An empty text is zero.
+func ParseWeight(text string) (int, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return 0, nil
+	}
+	factor := 1.0
+	number := strings.TrimSpace(strings.TrimSuffix(text, "g"))
+	if strings.HasSuffix(text, "kg") {
+		factor = 1000
+		number = strings.TrimSpace(strings.TrimSuffix(text, "kg"))
+	}
+	weight, err := strconv.ParseFloat(strings.Replace(number, ",", ".", 1), 64)
+	if err != nil || weight < 0 {
+		return 0, fmt.Errorf("invalid weight %q, use e.g. 350 g or 1.2 kg", text)
+	}
+	return int(math.Round(weight * factor)), nil
+}
+
+// FormatWeight renders grams as "350 g" or "1.25 kg".
+func FormatWeight(grams int) string {
+	if grams < 1000 {
+		return fmt.Sprintf("%d g", grams)
+	}
+	return strconv.FormatFloat(float64(grams)/1000, 'f', -1, 64) + " kg"
+}
+
+// RenderGearMarkdown renders the packing list as a markdown checklist with
+// the pack weight, followed by the free-text notes, for consumers that only
+// read the Equipment text.
+func RenderGearMarkdown(list GearList, notes string) string {
+	var md strings.Builder
+	for _, item := range list.Items {
+		fmt.Fprintf(&md, "- %s", item.Name)
+		if item.WeightGrams > 0 {
+			fmt.Fprintf(&md, " (%s)", FormatWeight(item.WeightGrams))
+		}
+		if item.Optional {
+			md.WriteString(" *optional*")
+		}
+		md.WriteString("\n")
+	}
+	fmt.Fprintf(&md, "\n**Pack weight:** %s", FormatWeight(list.TotalWeightGrams))
+	if list.RequiredWeightGrams != list.TotalWeightGrams {
+		fmt.Fprintf(&md, " (%s without optional items)", FormatWeight(list.RequiredWeightGrams))
+	}
+	md.WriteString("\n")
+	if notes = strings.TrimSpace(notes); notes != "" {
+		fmt.Fprintf(&md, "\n%s\n", notes)
+	}
+	return md.String()
+}
@@ -0,0 +1,119 @@
+package helpers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+)
+
+const earthRadiusKm = 6371.0
+
+type TrackPoint struct {
+	Lat float64
+	Lon float64
+	Ele float64
+}
+
+type TrackStats struct {
+	DistanceKm float64
+	AscentM    float64
+	DescentM   float64
+	MinEleM    float64
+	MaxEleM    float64
+}
+
+type gpxPoint struct {
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+	Ele float64 `xml:"ele"`
+}
+
+type gpxFile struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+	Routes []struct {
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+}
+
+// ParseGPX reads every track point of a GPX file, falling back to route
+// points when the file has no recorded track.
+func ParseGPX(filePath string) ([]TrackPoint, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GPX file: %v", err)
+	}
+
+	var gpx gpxFile
+	if err := xml.Unmarshal(data, &gpx); err != nil {
+		return nil, fmt.Errorf("failed to parse GPX file: %v", err)
+	}
+
+	var points []TrackPoint
+	for _, track := range gpx.Tracks {
+		for _, segment := range track.Segments {
+			for _, p := range segment.Points {
+				points = append(points, TrackPoint{Lat: p.Lat, Lon: p.Lon, Ele: p.Ele})
+			}
+		}
+	}
+	if len(points) == 0 {
+		for _, route := range gpx.Routes {
+			for _, p := range route.Points {
+				points = append(points, TrackPoint{Lat: p.Lat, Lon: p.Lon, Ele: p.Ele})
+			}
+		}
+	}
+	if len(points) < 2 {
+		return nil, fmt.Errorf("GPX file contains no track")
+	}
+	return points, nil
+}
+
+// CumulativeDistances returns the distance in kilometres from the first
+// point to every point of the track.
+func CumulativeDistances(points []TrackPoint) []float64 {
+	distances := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		distances[i] = distances[i-1] + haversineKm(points[i-1], points[i])
+	}
+	return distances
+}
+
+func ComputeTrackStats(points []TrackPoint) TrackStats {
+	stats := TrackStats{MinEleM: math.Inf(1), MaxEleM: math.Inf(-1)}
+	for i, p := range points {
+		stats.MinEleM = math.Min(stats.MinEleM, p.Ele)
+		stats.MaxEleM = math.Max(stats.MaxEleM, p.Ele)
+		if i == 0 {
+			continue
+		}
+		stats.DistanceKm += haversineKm(points[i-1], p)
+		if delta := p.Ele - points[i-1].Ele; delta > 0 {
+			stats.AscentM += delta
+		} else {
+			stats.DescentM -= delta
+		}
+	}
+
+	stats.DistanceKm = math.Round(stats.DistanceKm*100) / 100
+	stats.AscentM = math.Round(stats.AscentM)
+	stats.DescentM = math.Round(stats.DescentM)
+	stats.MinEleM = math.Round(stats.MinEleM)
+	stats.MaxEleM = math.Round(stats.MaxEleM)
+	return stats
+}
+
+func haversineKm(a, b TrackPoint) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,329 @@
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const rotatedJPEGQuality = 92
+
+// imageExtensions maps the detected content type of an image to the
+// extension of its key.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// jpegMetadataMarkers are the JPEG segments dropped before upload: APP1
+// other than EXIF (XMP and extended XMP), APP13 (Photoshop and IPTC, with
+// byline and location) and comments.
+var jpegMetadataMarkers = map[byte]bool{0xE1: true, 0xED: true, 0xFE: true}
+
+// pngTextChunks are the PNG chunks dropped before upload. Besides free
+// text such as the author they carry XMP under "XML:com.adobe.xmp".
+var pngTextChunks = map[string]bool{"tEXt": true, "zTXt": true, "iTXt": true}
+
+// UploadImage uploads a photo after removing private metadata and returns
+// the metadata read from the original file. The photo is copied to a
+// temporary file piece by piece, so only its metadata is held in memory
+// unless it has to be rotated.
+func UploadImage(ctx context.Context, key, filePath string, opts UploadOptions, progress ProgressFunc) (string, ImageMetadata, error) {
+	tempFile, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return "", ImageMetadata{}, fmt.Errorf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+	metadata, err := prepareImage(tempFile, filePath)
+	sniff := make([]byte, 512)
+	n, _ := tempFile.ReadAt(sniff, 0)
+	if closeErr := tempFile.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write temp file: %v", closeErr)
+	}
+	if err != nil {
+		return "", metadata, err
+	}
+
+	// The key and headers follow the format actually uploaded, which is not
+	// WebP for a JPEG, rotated or not.
+	contentType := http.DetectContentType(sniff[:n])
+	if extension, ok := imageExtensions[contentType]; ok {
+		key = strings.TrimSuffix(key, path.Ext(key)) + extension
+		opts.ContentType = contentType
+	}
+
+	if opts.OriginalFileName == "" {
+		opts.OriginalFileName = filepath.Base(filePath)
+	}
+	url, err := UploadToS3(ctx, key, tempFile.Name(), opts, progress)
+	return url, metadata, err
+}
+
+// prepareImage writes the photo at filePath to dst ready for upload. JPEGs
+// are rotated according to their EXIF orientation, which decodes and
+// re-encodes them without any metadata. All other images keep their EXIF
+// block with GPS and identifying tags blanked, and lose embedded XMP, IPTC
+// and text metadata, see stripImageMetadata.
+func prepareImage(dst *os.File, filePath string) (ImageMetadata, error) {
+	src, err := os.Open(filePath)
+	if err != nil {
+		return ImageMetadata{}, fmt.Errorf("failed to read file: %v", err)
+	}
+	defer src.Close()
+
+	reader := bufio.NewReader(src)
+	header, _ := reader.Peek(2)
+	isJPEG := len(header) == 2 && header[0] == 0xFF && header[1] == 0xD8
+	metadata, err := stripImageMetadata(dst, reader)
+	if err != nil || !isJPEG || metadata.Orientation <= 1 || metadata.Orientation > 8 {
+		return metadata, err
+	}
+
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return metadata, fmt.Errorf("failed to read file: %v", err)
+	}
+	img, err := jpeg.Decode(bufio.NewReader(src))
+	if err != nil {
+		return metadata, fmt.Errorf("failed to decode image: %v", err)
+	}
+	if err := dst.Truncate(0); err != nil {
+		return metadata, fmt.Errorf("failed to write temp file: %v", err)
+	}
+	if _, err := dst.Seek(0, io.SeekStart); err != nil {
+		return metadata, fmt.Errorf("failed to write temp file: %v", err)
+	}
+	if err := jpeg.Encode(dst, applyOrientation(img, metadata.Orientation), &jpeg.Options{Quality: rotatedJPEGQuality}); err != nil {
+		return metadata, fmt.Errorf("failed to encode image: %v", err)
+	}
+	return metadata, nil
+}
+
+// stripImageMetadata copies a JPEG, WebP or PNG image from src to dst one
+// segment or chunk at a time, blanking private EXIF tags. JPEGs lose their
+// XMP (including extended XMP), Photoshop/IPTC and comment segments, WebPs
+// their XMP chunk and PNGs all text chunks, which hold XMP as well. Only the
+// metadata is held in memory. The RIFF size of a WebP image is
+// corrected when dst is an io.WriterAt positioned at its start. Other
+// formats are copied unchanged.
+func stripImageMetadata(dst io.Writer, src *bufio.Reader) (ImageMetadata, error) {
+	header, _ := src.Peek(12)
+	switch {
+	case len(header) >= 2 && header[0] == 0xFF && header[1] == 0xD8:
+		return stripJPEGMetadata(dst, src)
+	case len(header) == 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return stripWebPMetadata(dst, src)
+	case len(header) >= 8 && string(header[1:4]) == "PNG":
+		return stripPNGMetadata(dst, src)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return ImageMetadata{Orientation: 1}, fmt.Errorf("failed to copy image: %v", err)
+	}
+	return ImageMetadata{Orientation: 1}, nil
+}
+
+// scrubExif reads the metadata of an EXIF block and then blanks its private
+// tags in place.
+func scrubExif(tiff []byte) (ImageMetadata, error) {
+	metadata, _ := parseExif(tiff)
+	reader, err := newExifReader(tiff)
+	if err == nil {
+		err = reader.scrub()
+	}
+	if err != nil {
+		return metadata, fmt.Errorf("failed to strip image metadata: %v", err)
+	}
+	return metadata, nil
+}
+
+func stripJPEGMetadata(dst io.Writer, src *bufio.Reader) (ImageMetadata, error) {
+	metadata := ImageMetadata{Orientation: 1}
+	if _, err := io.CopyN(dst, src, 2); err != nil {
+		return metadata, fmt.Errorf("failed to copy image: %v", err)
+	}
+	for {
+		head, err := src.Peek(4)
+		if err != nil || head[0] != 0xFF || head[1] == 0xDA || head[1] == 0xD9 {
+			break
+		}
+		marker, length := head[1], int(binary.BigEndian.Uint16(head[2:]))
+		if length < 2 {
+			break
+		}
+		segment := make([]byte, 2+length)
+		if _, err := io.ReadFull(src, segment); err != nil {
+			return metadata, fmt.Errorf("failed to read image: %v", err)
+		}
+		body := segment[4:]
+		isExif := marker == 0xE1 && bytes.HasPrefix(body, []byte("Exif\x00\x00"))
+		if jpegMetadataMarkers[marker] && !isExif {
+			continue
+		}
+		if isExif {
+			if metadata, err = scrubExif(body[6:]); err != nil {
+				return metadata, err
+			}
+		}
+		if _, err := dst.Write(segment); err != nil {
+			return metadata, fmt.Errorf("failed to copy image: %v", err)
+		}
+	}
+	// The compressed image data follows the metadata segments.
+	if _, err := io.Copy(dst, src); err != nil {
+		return metadata, fmt.Errorf("failed to copy image: %v", err)
+	}
+	return metadata, nil
+}
+
+func stripWebPMetadata(dst io.Writer, src *bufio.Reader) (ImageMetadata, error) {
+	metadata := ImageMetadata{Orientation: 1}
+	if _, err := io.CopyN(dst, src, 12); err != nil {
+		return metadata, fmt.Errorf("failed to copy image: %v", err)
+	}
+	riffSize := int64(4) // the "WEBP" form type
+	for {
+		chunk := make([]byte, 8)
+		if _, err := io.ReadFull(src, chunk); err != nil {
+			break
+		}
+		fourCC, size := string(chunk[:4]), int64(binary.LittleEndian.Uint32(chunk[4:]))
+		padded := size + size%2
+
+		switch fourCC {
+		case "XMP ":
+			// A truncated chunk ends the loop with the next read.
+			io.CopyN(io.Discard, src, padded)
+			continue
+		case "VP8X", "EXIF":
+			body := make([]byte, padded)
+			n, _ := io.ReadFull(src, body)
+			body = body[:n]
+			if fourCC == "VP8X" && n > 0 {
+				body[0] &^= 0x04 // XMP metadata flag
+			}
+			if fourCC == "EXIF" {
+				var err error
+				if metadata, err = scrubExif(bytes.TrimPrefix(body[:min(int64(n), size)], []byte("Exif\x00\x00"))); err != nil {
+					return metadata, err
+				}
+			}
+			if _, err := dst.Write(append(chunk, body...)); err != nil {
+				return metadata, fmt.Errorf("failed to copy image: %v", err)
+			}
+			riffSize += 8 + int64(n)
+		default:
+			if _, err := dst.Write(chunk); err != nil {
+				return metadata, fmt.Errorf("failed to copy image: %v", err)
+			}
+			n, err := io.CopyN(dst, src, padded)
+			riffSize += 8 + n
+			if err != nil && err != io.EOF {
+				return metadata, fmt.Errorf("failed to copy image: %v", err)
+			}
+		}
+	}
+
+	if at, ok := dst.(io.WriterAt); ok {
+		size := make([]byte, 4)
+		binary.LittleEndian.PutUint32(size, uint32(riffSize))
+		if _, err := at.WriteAt(size, 4); err != nil {
+			return metadata, fmt.Errorf("failed to copy image: %v", err)
+		}
+	}
+	return metadata, nil
+}
+
+func stripPNGMetadata(dst io.Writer, src *bufio.Reader) (ImageMetadata, error) {
+	metadata := ImageMetadata{Orientation: 1}
+	if _, err := io.CopyN(dst, src, 8); err != nil {
+		return metadata, fmt.Errorf("failed to copy image: %v", err)
+	}
+	for {
+		head := make([]byte, 8)
+		if _, err := io.ReadFull(src, head); err != nil {
+			break
+		}
+		size := int64(binary.BigEndian.Uint32(head))
+		if pngTextChunks[string(head[4:8])] {
+			if _, err := io.CopyN(io.Discard, src, size+4); err != nil {
+				break
+			}
+			continue
+		}
+		if string(head[4:8]) != "eXIf" {
+			if _, err := dst.Write(head); err != nil {
+				return metadata, fmt.Errorf("failed to copy image: %v", err)
+			}
+			if _, err := io.CopyN(dst, src, size+4); err != nil && err != io.EOF {
+				return metadata, fmt.Errorf("failed to copy image: %v", err)
+			}
+			continue
+		}
+
+		body := make([]byte, size+4) // data and CRC
+		if _, err := io.ReadFull(src, body); err != nil {
+			return metadata, fmt.Errorf("failed to read image: %v", err)
+		}
+		var err error
+		if metadata, err = scrubExif(body[:size]); err != nil {
+			return metadata, err
+		}
+		crc := crc32.NewIEEE()
+		crc.Write(head[4:8])
+		crc.Write(body[:size])
+		binary.BigEndian.PutUint32(body[size:], crc.Sum32())
+		if _, err := dst.Write(append(head, body...)); err != nil {
+			return metadata, fmt.Errorf("failed to copy image: %v", err)
+		}
+	}
+	return metadata, nil
+}
+
+// applyOrientation turns an image upright for the given EXIF orientation.
+func applyOrientation(src image.Image, orientation int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			default:
+				dx, dy = x, y
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
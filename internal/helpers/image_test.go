@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// gpsXMP is an XMP packet placing the photo at a private location.
+const gpsXMP = `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+	`<rdf:Description xmlns:exif="http://ns.adobe.com/exif/1.0/" exif:GPSLatitude="47,30.1234N" exif:GPSLongitude="11,05.4321E"/>` +
+	`</rdf:RDF></x:xmpmeta>`
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 60), uint8(y * 80), 0, 255})
+		}
+	}
+	return img
+}
+
+func jpegSegment(marker byte, body string) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(body)+2))
+	return append(segment, body...)
+}
+
+func pngChunk(kind, data string) []byte {
+	chunk := make([]byte, 4, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	chunk = append(chunk, kind+data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE([]byte(kind+data)))
+}
+
+func prepareFixture(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, name)
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dst, err := os.Create(filepath.Join(dir, "prepared"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	if _, err := prepareImage(dst, filePath); err != nil {
+		t.Fatalf("prepareImage: %v", err)
+	}
+	prepared, err := os.ReadFile(dst.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prepared
+}
+
+func TestPrepareImageDropsJPEGXMP(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := append([]byte{}, encoded.Bytes()[:2]...)
+	data = append(data, jpegSegment(0xE1, "http://ns.adobe.com/xap/1.0/\x00"+gpsXMP)...)
+	data = append(data, jpegSegment(0xE1, "http://ns.adobe.com/xmp/extension/\x00"+gpsXMP)...)
+	data = append(data, jpegSegment(0xED, "Photoshop 3.0\x008BIM"+gpsXMP)...)
+	data = append(data, encoded.Bytes()[2:]...)
+
+	prepared := prepareFixture(t, "photo.jpg", data)
+	if bytes.Contains(prepared, []byte("GPSLatitude")) {
+		t.Error("prepared JPEG still contains the XMP location")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(prepared)); err != nil {
+		t.Errorf("prepared JPEG does not decode: %v", err)
+	}
+}
+
+func TestPrepareImageDropsPNGXMP(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	// The signature and IHDR chunk come first.
+	const ihdrEnd = 8 + 12 + 13
+	data := append([]byte{}, encoded.Bytes()[:ihdrEnd]...)
+	data = append(data, pngChunk("iTXt", "XML:com.adobe.xmp\x00\x00\x00\x00\x00"+gpsXMP)...)
+	data = append(data, pngChunk("tEXt", "Comment\x00"+gpsXMP)...)
+	data = append(data, encoded.Bytes()[ihdrEnd:]...)
+
+	prepared := prepareFixture(t, "photo.png", data)
+	if bytes.Contains(prepared, []byte("GPSLatitude")) {
+		t.Error("prepared PNG still contains the XMP location")
+	}
+	if _, err := png.Decode(bytes.NewReader(prepared)); err != nil {
+		t.Errorf("prepared PNG does not decode: %v", err)
+	}
+}
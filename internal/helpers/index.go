@@ -0,0 +1,76 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// IndexFile is the generated overview of all published documents, written
+// next to them in the output folder.
+const IndexFile = "index.json"
+
+// IndexEntry summarizes a published document for the generated index.
+type IndexEntry struct {
+	DocumentRef
+	File         string
+	Author       string
+	Participants []string
+	Tags         []string
+}
+
+// GeneratedIndex lists every published document, the tags in use with
+// their counts and, per person, the documents they wrote or took part in.
+type GeneratedIndex struct {
+	Documents []IndexEntry
+	Tags      []TagCount
+	People    []PersonListing
+}
+
+// BuildIndex summarizes the documents, sorted by ID.
+func BuildIndex(documents []Document) GeneratedIndex {
+	index := GeneratedIndex{Documents: []IndexEntry{}, Tags: CountTags(documents), People: ListPeople(documents)}
+	for _, document := range documents {
+		var tags []string
+		items, _ := document.Data["Tags"].([]interface{})
+		for _, item := range items {
+			if tag, ok := item.(string); ok {
+				tags = append(tags, tag)
+			}
+		}
+		file, err := filepath.Rel(OutputFolder, document.Path)
+		if err != nil {
+			file = document.Path
+		}
+		index.Documents = append(index.Documents, IndexEntry{
+			DocumentRef:  newDocumentRef(document),
+			File:         filepath.ToSlash(file),
+			Author:       document.Author(),
+			Participants: document.Participants(),
+			Tags:         NormalizeTags(tags),
+		})
+	}
+	sort.Slice(index.Documents, func(i, j int) bool { return index.Documents[i].ID < index.Documents[j].ID })
+	return index
+}
+
+// WriteIndex regenerates index.json in the output folder from the documents
+// on disk and returns it.
+func WriteIndex() (GeneratedIndex, error) {
+	documents, err := LoadDocuments()
+	if err != nil {
+		return GeneratedIndex{}, err
+	}
+	index := BuildIndex(documents)
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return index, fmt.Errorf("failed to encode index: %v", err)
+	}
+	path := filepath.Join(OutputFolder, IndexFile)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return index, fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return index, nil
+}
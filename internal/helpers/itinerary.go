@@ -0,0 +1,124 @@
+package helpers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxItineraryDays guards against generating days for mistyped dates.
+const maxItineraryDays = 366
+
+// ItineraryDay is the plan of one day of a trip. EventID links the event
+// published for that day.
+type ItineraryDay struct {
+	Date           string
+	Title          string
+	StartLocation  string
+	EndLocation    string
+	DistanceKm     float64
+	ElevationGainM int
+	ElevationLossM int
+	EventID        string
+	Accommodation  string
+}
+
+// dateLayoutOf returns the layout a date is written in.
+func dateLayoutOf(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, text); err == nil {
+			return layout, true
+		}
+	}
+	return "", false
+}
+
+// TripDays returns every date from start to end, written in the same
+// format as start.
+func TripDays(start, end string) ([]string, error) {
+	layout, ok := dateLayoutOf(start)
+	if !ok {
+		return nil, fmt.Errorf("invalid trip start date %q", start)
+	}
+	first, _ := ParseDate(start)
+	last, ok := ParseDate(end)
+	if !ok {
+		return nil, fmt.Errorf("invalid trip end date %q", end)
+	}
+	if last.Before(first) {
+		return nil, fmt.Errorf("the trip ends on %s, before it starts on %s", end, start)
+	}
+	if days := int(last.Sub(first).Hours()/24) + 1; days > maxItineraryDays {
+		return nil, fmt.Errorf("the trip lasts %d days, at most %d are supported", days, maxItineraryDays)
+	}
+
+	var days []string
+	for date := first; !date.After(last); date = date.AddDate(0, 0, 1) {
+		days = append(days, date.Format(layout))
+	}
+	return days, nil
+}
+
+// ValidateItinerary checks that every day has a date within the trip and
+// that no date is planned twice, then sorts the days by date.
+func ValidateItinerary(days []ItineraryDay, start, end string) error {
+	if len(days) == 0 {
+		return nil
+	}
+	first, ok := ParseDate(start)
+	if !ok {
+		return fmt.Errorf("invalid trip start date %q", start)
+	}
+	last, ok := ParseDate(end)
+	if !ok {
+		return fmt.Errorf("invalid trip end date %q", end)
+	}
+
+	seen := make(map[time.Time]bool)
+	for i, day := range days {
+		date, ok := ParseDate(day.Date)
+		if !ok {
+			return fmt.Errorf("itinerary day %d has an invalid date %q", i+1, day.Date)
+		}
+		if date.Before(first) || date.After(last) {
+			return fmt.Errorf("itinerary date %s is outside the trip (%s – %s)", day.Date, start, end)
+		}
+		if seen[date] {
+			return fmt.Errorf("itinerary date %s is planned twice", day.Date)
+		}
+		seen[date] = true
+	}
+
+	SortItinerary(days)
+	return nil
+}
+
+// SortItinerary orders days by date, keeping days without a valid date at
+// the end.
+func SortItinerary(days []ItineraryDay) {
+	sort.SliceStable(days, func(i, j int) bool {
+		a, okA := ParseDate(days[i].Date)
+		b, okB := ParseDate(days[j].Date)
+		if okA != okB {
+			return okA
+		}
+		return a.Before(b)
+	})
+}
+
+// ParseElevation reads a height difference in metres, accepting an
+// optional "m" suffix. An empty text is zero.
+func ParseElevation(text string) (int, error) {
+	text = strings.TrimSpace(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(text)), "m"))
+	if text == "" {
+		return 0, nil
+	}
+	metres, err := strconv.Atoi(text)
+	if err != nil || metres < 0 {
+		return 0, fmt.Errorf("invalid elevation %q, enter metres", text)
+	}
+	return metres, nil
+}
@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Location struct {
+	Name      string
+	Region    string
+	Country   string
+	Latitude  *float64
+	Longitude *float64
+}
+
+// NewLocation builds a Location from form input. It returns nil when every
+// field is empty so documents without a location omit it.
+func NewLocation(name, region, country, latitude, longitude string) (*Location, error) {
+	location := &Location{
+		Name:    strings.TrimSpace(name),
+		Region:  strings.TrimSpace(region),
+		Country: strings.TrimSpace(country),
+	}
+
+	latitude, longitude = strings.TrimSpace(latitude), strings.TrimSpace(longitude)
+	if (latitude == "") != (longitude == "") {
+		return nil, fmt.Errorf("Latitude and longitude must be given together")
+	}
+	if latitude != "" {
+		lat, err := strconv.ParseFloat(latitude, 64)
+		if err != nil || lat < -90 || lat > 90 {
+			return nil, fmt.Errorf("Invalid latitude: %s", latitude)
+		}
+		lon, err := strconv.ParseFloat(longitude, 64)
+		if err != nil || lon < -180 || lon > 180 {
+			return nil, fmt.Errorf("Invalid longitude: %s", longitude)
+		}
+		location.Latitude, location.Longitude = &lat, &lon
+	}
+
+	if *location == (Location{}) {
+		return nil, nil
+	}
+	return location, nil
+}
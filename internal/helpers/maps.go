@@ -0,0 +1,176 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultMapZoom = 14
+
+type KomootTour struct {
+	TourID     string
+	ShareToken string
+	URL        string
+	EmbedURL   string
+}
+
+type GoogleMapLocation struct {
+	URL       string
+	EmbedURL  string
+	Latitude  float64
+	Longitude float64
+	Zoom      float64
+}
+
+var (
+	komootTourIDPattern = regexp.MustCompile(`^\d+$`)
+	komootTourPattern   = regexp.MustCompile(`komoot\.[a-z]+/(?:[a-z]{2}-[a-z]{2}/)?tour/(\d+)`)
+	iframeSrcPattern    = regexp.MustCompile(`src=["']([^"']+)["']`)
+	mapAtPattern        = regexp.MustCompile(`@(-?\d+(?:\.\d+)?),(-?\d+(?:\.\d+)?)(?:,(\d+(?:\.\d+)?)z)?`)
+	mapPairPattern      = regexp.MustCompile(`^\s*(-?\d+(?:\.\d+)?)\s*,\s*(-?\d+(?:\.\d+)?)\s*$`)
+	mapEmbedLatPattern  = regexp.MustCompile(`!3d(-?\d+(?:\.\d+)?)`)
+	mapEmbedLonPattern  = regexp.MustCompile(`!2d(-?\d+(?:\.\d+)?)`)
+	mapDataLatPattern   = regexp.MustCompile(`!8m2!3d(-?\d+(?:\.\d+)?)!4d(-?\d+(?:\.\d+)?)`)
+)
+
+// ParseKomoot accepts a tour ID, a tour URL or an embed code and returns the
+// canonical tour link together with its embed URL.
+func ParseKomoot(input string) (KomootTour, error) {
+	input = strings.TrimSpace(input)
+	if match := iframeSrcPattern.FindStringSubmatch(input); match != nil {
+		input = match[1]
+	}
+
+	var tour KomootTour
+	if komootTourIDPattern.MatchString(input) {
+		tour.TourID = input
+	} else {
+		match := komootTourPattern.FindStringSubmatch(input)
+		if match == nil {
+			return KomootTour{}, fmt.Errorf("not a Komoot tour: %s", input)
+		}
+		tour.TourID = match[1]
+		if parsed, err := url.Parse(input); err == nil {
+			tour.ShareToken = parsed.Query().Get("share_token")
+		}
+	}
+
+	tour.URL = fmt.Sprintf("https://www.komoot.com/tour/%s", tour.TourID)
+	tour.EmbedURL = fmt.Sprintf("https://www.komoot.com/tour/%s/embed?profile=1", tour.TourID)
+	if tour.ShareToken != "" {
+		tour.URL += "?share_token=" + url.QueryEscape(tour.ShareToken)
+		tour.EmbedURL += "&share_token=" + url.QueryEscape(tour.ShareToken)
+	}
+	return tour, nil
+}
+
+// IsGoogleMapShortLink reports whether the input is a goo.gl share link,
+// which has to be resolved with ResolveShortLink before it can be parsed.
+func IsGoogleMapShortLink(input string) bool {
+	return strings.Contains(input, "goo.gl/")
+}
+
+// ParseGoogleMap accepts a Google Maps link, embed code or a plain
+// "lat,lon" pair and extracts the coordinates it points at. Share links
+// must be resolved first.
+func ParseGoogleMap(input string) (GoogleMapLocation, error) {
+	input = strings.TrimSpace(input)
+	if match := iframeSrcPattern.FindStringSubmatch(input); match != nil {
+		input = match[1]
+	}
+	if IsGoogleMapShortLink(input) {
+		return GoogleMapLocation{}, fmt.Errorf("short link not resolved: %s", input)
+	}
+
+	lat, lon, zoom, err := extractCoordinates(input)
+	if err != nil {
+		return GoogleMapLocation{}, err
+	}
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return GoogleMapLocation{}, fmt.Errorf("coordinates out of range: %s", input)
+	}
+
+	coords := fmt.Sprintf("%s,%s", FormatCoordinate(lat), FormatCoordinate(lon))
+	return GoogleMapLocation{
+		URL:       fmt.Sprintf("https://www.google.com/maps/@%s,%gz", coords, zoom),
+		EmbedURL:  fmt.Sprintf("https://maps.google.com/maps?q=%s&z=%g&output=embed", coords, zoom),
+		Latitude:  lat,
+		Longitude: lon,
+		Zoom:      zoom,
+	}, nil
+}
+
+func extractCoordinates(input string) (lat, lon, zoom float64, err error) {
+	zoom = defaultMapZoom
+	parseFloat := func(s string) float64 {
+		v, _ := strconv.ParseFloat(s, 64)
+		return v
+	}
+
+	if match := mapPairPattern.FindStringSubmatch(input); match != nil {
+		return parseFloat(match[1]), parseFloat(match[2]), zoom, nil
+	}
+
+	parsed, err := url.Parse(input)
+	if err != nil || !strings.Contains(parsed.Host, "google.") {
+		return 0, 0, 0, fmt.Errorf("not a Google Maps link: %s", input)
+	}
+	query := parsed.Query()
+	if z := query.Get("z"); z != "" {
+		zoom = parseFloat(z)
+	}
+
+	// A place marker in the data segment is more precise than the viewport centre.
+	if match := mapDataLatPattern.FindStringSubmatch(input); match != nil {
+		lat, lon = parseFloat(match[1]), parseFloat(match[2])
+		if at := mapAtPattern.FindStringSubmatch(parsed.Path); at != nil && at[3] != "" {
+			zoom = parseFloat(at[3])
+		}
+		return lat, lon, zoom, nil
+	}
+	if match := mapAtPattern.FindStringSubmatch(parsed.Path); match != nil {
+		if match[3] != "" {
+			zoom = parseFloat(match[3])
+		}
+		return parseFloat(match[1]), parseFloat(match[2]), zoom, nil
+	}
+	for _, key := range []string{"q", "query", "ll", "center", "destination"} {
+		if match := mapPairPattern.FindStringSubmatch(query.Get(key)); match != nil {
+			return parseFloat(match[1]), parseFloat(match[2]), zoom, nil
+		}
+	}
+	if pb := query.Get("pb"); pb != "" {
+		latMatch := mapEmbedLatPattern.FindStringSubmatch(pb)
+		lonMatch := mapEmbedLonPattern.FindStringSubmatch(pb)
+		if latMatch != nil && lonMatch != nil {
+			return parseFloat(latMatch[1]), parseFloat(lonMatch[1]), zoom, nil
+		}
+	}
+	return 0, 0, 0, fmt.Errorf("no coordinates found in Google Maps link: %s", input)
+}
+
+// ResolveShortLink follows the redirects of a share link and returns the
+// link it ends at.
+func ResolveShortLink(ctx context.Context, link string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSpace(link), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve short link: %v", err)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve short link: %v", err)
+	}
+	defer resp.Body.Close()
+	return resp.Request.URL.String(), nil
+}
+
+func FormatCoordinate(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// deleteBatchSize is the most keys S3 accepts in one DeleteObjects call.
+const deleteBatchSize = 1000
+
+// ObjectInfo describes an object in the bucket.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// ListObjects returns every object whose key starts with prefix.
+func ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	cfg := LoadConfig()
+	sess, err := newAWSSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []ObjectInfo
+	err = s3.New(sess).ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(cfg.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:          aws.StringValue(object.Key),
+				Size:         aws.Int64Value(object.Size),
+				LastModified: aws.TimeValue(object.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects under %s: %v", prefix, err)
+	}
+	return objects, nil
+}
+
+// ListDocumentPrefixes returns the top-level prefixes in the bucket, which
+// are the IDs of documents that have uploads.
+func ListDocumentPrefixes(ctx context.Context) ([]string, error) {
+	cfg := LoadConfig()
+	sess, err := newAWSSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixes []string
+	err = s3.New(sess).ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(cfg.Bucket),
+		Delimiter: aws.String("/"),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, prefix := range page.CommonPrefixes {
+			if id := strings.TrimSuffix(aws.StringValue(prefix.Prefix), "/"); id != DraftPrefix {
+				prefixes = append(prefixes, id)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bucket: %v", err)
+	}
+	return prefixes, nil
+}
+
+// DeleteObjects removes the given keys from the bucket.
+func DeleteObjects(ctx context.Context, keys []string) error {
+	cfg := LoadConfig()
+	sess, err := newAWSSession(cfg)
+	if err != nil {
+		return err
+	}
+
+	svc := s3.New(sess)
+	for start := 0; start < len(keys); start += deleteBatchSize {
+		batch := keys[start:min(start+deleteBatchSize, len(keys))]
+		identifiers := make([]*s3.ObjectIdentifier, len(batch))
+		for i, key := range batch {
+			identifiers[i] = &s3.ObjectIdentifier{Key: aws.String(key)}
+		}
+
+		output, err := svc.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(cfg.Bucket),
+			Delete: &s3.Delete{Objects: identifiers, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete objects: %v", err)
+		}
+		if len(output.Errors) > 0 {
+			first := output.Errors[0]
+			return fmt.Errorf("failed to delete %d objects, %s: %s", len(output.Errors), aws.StringValue(first.Key), aws.StringValue(first.Message))
+		}
+		uploadHashes.forget(batch...)
+	}
+	return nil
+}
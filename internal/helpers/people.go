@@ -0,0 +1,157 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const peopleFile = "people.json"
+
+// Person is an entry of the local people directory used to autocomplete
+// authors and participants.
+type Person struct {
+	Name  string
+	Email string
+}
+
+// LoadPeople reads people.json from the working directory. A missing file
+// is an empty directory.
+func LoadPeople() ([]Person, error) {
+	data, err := os.ReadFile(peopleFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", peopleFile, err)
+	}
+	var people []Person
+	if err := json.Unmarshal(data, &people); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", peopleFile, err)
+	}
+	return people, nil
+}
+
+// AddPeople adds the names missing from people.json to it.
+func AddPeople(names ...string) error {
+	people, err := LoadPeople()
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(people))
+	for _, person := range people {
+		known[strings.ToLower(person.Name)] = true
+	}
+	added := false
+	for _, name := range NormalizeNames(names) {
+		if !known[strings.ToLower(name)] {
+			people = append(people, Person{Name: name})
+			added = true
+		}
+	}
+	if !added {
+		return nil
+	}
+
+	sort.Slice(people, func(i, j int) bool { return strings.ToLower(people[i].Name) < strings.ToLower(people[j].Name) })
+	data, err := json.MarshalIndent(people, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode people: %v", err)
+	}
+	if err := os.WriteFile(peopleFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", peopleFile, err)
+	}
+	return nil
+}
+
+// PeopleNames returns the names in the people directory together with
+// everyone named in a published document, sorted.
+func PeopleNames() []string {
+	people, _ := LoadPeople()
+	var names []string
+	for _, person := range people {
+		names = append(names, person.Name)
+	}
+	for _, document := range DocumentIndex().All() {
+		names = append(names, document.People()...)
+	}
+	names = NormalizeNames(names)
+	sort.Slice(names, func(i, j int) bool { return strings.ToLower(names[i]) < strings.ToLower(names[j]) })
+	return names
+}
+
+// Author returns who wrote the document.
+func (d Document) Author() string {
+	return strings.TrimSpace(d.Text("Author"))
+}
+
+// Participants returns who attended an event or trip.
+func (d Document) Participants() []string {
+	items, _ := d.Data["Participants"].([]interface{})
+	var names []string
+	for _, item := range items {
+		if name, ok := item.(string); ok {
+			names = append(names, name)
+		}
+	}
+	return NormalizeNames(names)
+}
+
+// People returns the author followed by the participants.
+func (d Document) People() []string {
+	return NormalizeNames(append([]string{d.Author()}, d.Participants()...))
+}
+
+// DocumentRef identifies a document in listings.
+type DocumentRef struct {
+	ID    string
+	Type  string
+	Name  string
+	Dates string
+}
+
+func newDocumentRef(document Document) DocumentRef {
+	return DocumentRef{ID: document.ID, Type: document.Type, Name: document.Name(), Dates: document.Dates()}
+}
+
+// PersonListing is everything a person wrote or took part in.
+type PersonListing struct {
+	Name         string
+	Authored     []DocumentRef
+	Participated []DocumentRef
+}
+
+// ListPeople groups the documents by the people named in them, sorted by
+// name.
+func ListPeople(documents []Document) []PersonListing {
+	listings := make(map[string]*PersonListing)
+	listing := func(name string) *PersonListing {
+		key := strings.ToLower(name)
+		if listings[key] == nil {
+			listings[key] = &PersonListing{Name: name}
+		}
+		return listings[key]
+	}
+
+	sorted := append([]Document{}, documents...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	for _, document := range sorted {
+		if author := document.Author(); author != "" {
+			entry := listing(author)
+			entry.Authored = append(entry.Authored, newDocumentRef(document))
+		}
+		for _, participant := range document.Participants() {
+			entry := listing(participant)
+			entry.Participated = append(entry.Participated, newDocumentRef(document))
+		}
+	}
+
+	result := make([]PersonListing, 0, len(listings))
+	for _, entry := range listings {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name) })
+	return result
+}
@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// PresignKey returns a time-limited GET URL for a key in the bucket.
+func PresignKey(key string, expiry time.Duration) (string, error) {
+	cfg := LoadConfig()
+	sess, err := newAWSSession(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	req, _ := s3.New(sess).GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(cfg.Bucket),
+		Key:    aws.String(key),
+	})
+	signed, err := req.Presign(expiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign %s: %v", key, err)
+	}
+	return signed, nil
+}
+
+// PreviewURL returns a URL the publisher can open to look at an uploaded
+// file. Files in a private bucket get a pre-signed URL; anything else is
+// returned unchanged.
+func PreviewURL(rawURL string) (string, error) {
+	cfg := LoadConfig()
+	key, ok := KeyFromURL(rawURL)
+	if !cfg.PrivateBucket || !ok {
+		return rawURL, nil
+	}
+	return PresignKey(key, time.Duration(cfg.PresignExpiryMinutes)*time.Minute)
+}
@@ -0,0 +1,19 @@
+package helpers
+
+import "log"
+
+// DocumentPublished updates everything derived from the output folder
+// after the publisher wrote a document.
+func DocumentPublished(path string) {
+	if err := ReindexDocument(path); err != nil {
+		log.Printf("failed to update search index: %v", err)
+	}
+	if document, ok, err := LoadDocument(path); err == nil && ok {
+		if err := AddPeople(document.People()...); err != nil {
+			log.Printf("failed to update people directory: %v", err)
+		}
+	}
+	if _, err := WriteIndex(); err != nil {
+		log.Printf("failed to update %s: %v", IndexFile, err)
+	}
+}
@@ -0,0 +1,36 @@
+package helpers
+
+import (
+	"context"
+	"time"
+)
+
+const maxRetryDelay = 30 * time.Second
+
+// RetryWithBackoff runs fn until it succeeds, the attempts are used up or the
+// context is cancelled, doubling the delay after every failure.
+func RetryWithBackoff(ctx context.Context, attempts int, delay time.Duration, fn func() error, onRetry func(attempt int, err error)) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt == attempts {
+			break
+		}
+		if onRetry != nil {
+			onRetry(attempt+1, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+	return err
+}
@@ -1,43 +1,254 @@
 package helpers
 
 import (
-	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 )
 
 const (
-	awsRegion  = "us-east-1"
-	bucketName = "hikes-trailfinder-website-images"
+	sha256MetadataKey       = "Sha256"
+	documentIDMetadataKey   = "Document-Id"
+	documentTypeMetadataKey = "Document-Type"
 )
 
-func UploadToS3(key, filePath string) (string, error) {
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(awsRegion),
-	})
+// unsafeTagChars matches characters S3 does not accept in tag values.
+var unsafeTagChars = regexp.MustCompile(`[^\p{L}\p{Z}\p{N}_.:/=+\-@]`)
+
+// ProgressFunc receives the fraction of an upload sent so far, from 0 to 1.
+type ProgressFunc func(fraction float64)
+
+// UploadOptions sets the headers, metadata and tags stored with an
+// uploaded object. An empty ContentType is derived from the key's
+// extension; empty metadata fields are left out.
+type UploadOptions struct {
+	ContentType        string
+	ContentDisposition string
+
+	DocumentID       string
+	DocumentType     string
+	OriginalFileName string
+	AltText          string
+}
+
+// metadata returns the user metadata to store with the object, so objects
+// can be traced back to their document.
+func (o UploadOptions) metadata(cfg Config, sha256 string) map[string]*string {
+	metadata := map[string]*string{sha256MetadataKey: aws.String(sha256)}
+	for key, value := range map[string]string{
+		documentIDMetadataKey:   o.DocumentID,
+		documentTypeMetadataKey: o.DocumentType,
+		"Uploader":              cfg.Uploader,
+		"Original-Filename":     o.OriginalFileName,
+		"Alt-Text":              o.AltText,
+	} {
+		if value != "" {
+			// Metadata travels in HTTP headers, which only carry ASCII.
+			metadata[key] = aws.String(mime.QEncoding.Encode("utf-8", value))
+		}
+	}
+	return metadata
+}
+
+// objectTags returns the URL-encoded tag set of an object.
+func objectTags(documentID, documentType string) *string {
+	tags := url.Values{}
+	for key, value := range map[string]string{"DocumentID": documentID, "DocumentType": documentType} {
+		if value = unsafeTagChars.ReplaceAllString(value, "_"); value != "" {
+			tags.Set(key, value)
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return aws.String(tags.Encode())
+}
+
+// UploadToS3 streams a file to the bucket, switching to a multipart upload
+// for files larger than one part. S3 checks every request against the
+// Content-MD5 the SDK sends, and a single-part upload also against the
+// file's SHA-256; the bytes sent are hashed on the way and must match the
+// file, otherwise the object is deleted again. Content that is already in
+// the bucket is not uploaded again but copied within the bucket to the key,
+// so the document never links to an object another document may replace or
+// delete.
+func UploadToS3(ctx context.Context, key, filePath string, opts UploadOptions, progress ProgressFunc) (string, error) {
+	cfg := LoadConfig()
+	sess, err := newAWSSession(cfg)
 	if err != nil {
-		return "", fmt.Errorf("failed to create AWS session: %v", err)
+		return "", err
 	}
 
-	file, err := ioutil.ReadFile(filePath)
+	partSize := max(cfg.UploadPartSizeMB*1024*1024, s3manager.MinUploadPartSize)
+	sums, err := computeFileChecksums(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %v", err)
+		return "", err
 	}
 
+	if opts.ContentType == "" {
+		opts.ContentType = contentTypeFor(key)
+	}
 	svc := s3.New(sess)
-	_, err = svc.PutObject(&s3.PutObjectInput{
-		Bucket:      aws.String(bucketName),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(file),
-		ContentType: aws.String("image/webp"),
+	if existingKey, ok := findExistingObject(ctx, svc, cfg.Bucket, sums, key); ok {
+		if existingKey != key {
+			if err := copyExistingObject(ctx, svc, cfg, existingKey, key, opts, sums.sha256); err != nil {
+				return "", err
+			}
+		}
+		if progress != nil {
+			progress(1)
+		}
+		return objectURL(cfg, key), nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	defer file.Close()
+
+	var contentDisposition, cacheControl, checksum *string
+	if opts.ContentDisposition != "" {
+		contentDisposition = aws.String(opts.ContentDisposition)
+	}
+	if cfg.CacheControl != "" {
+		cacheControl = aws.String(cfg.CacheControl)
+	}
+	if sums.size < partSize {
+		// Multipart uploads ignore it; their parts are checked by Content-MD5.
+		checksum = aws.String(base64.StdEncoding.EncodeToString(sums.digest))
+	}
+
+	uploader := s3manager.NewUploader(sess, func(u *s3manager.Uploader) {
+		u.PartSize = partSize
+		u.Concurrency = max(cfg.UploadConcurrency, 1)
+	})
+	sent := sha256.New()
+	_, err = uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:             aws.String(cfg.Bucket),
+		Key:                aws.String(key),
+		Body:               &progressReader{Reader: io.TeeReader(file, sent), total: sums.size, progress: progress},
+		ContentType:        aws.String(opts.ContentType),
+		ContentDisposition: contentDisposition,
+		CacheControl:       cacheControl,
+		ChecksumSHA256:     checksum,
+		Metadata:           opts.metadata(cfg, sums.sha256),
+		Tagging:            objectTags(opts.DocumentID, opts.DocumentType),
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to upload file to S3: %v", err)
 	}
 
-	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucketName, awsRegion, key), nil
+	// The Sha256 metadata must never describe other content, or a later
+	// upload would take the object for a finished copy of its file.
+	if sentHash := hex.EncodeToString(sent.Sum(nil)); sentHash != sums.sha256 {
+		_, deleteErr := svc.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(cfg.Bucket),
+			Key:    aws.String(key),
+		})
+		if deleteErr != nil {
+			return "", fmt.Errorf("checksum mismatch for %s, the file changed during the upload, and failed to delete it: %v", key, deleteErr)
+		}
+		return "", fmt.Errorf("checksum mismatch for %s, the file changed during the upload", key)
+	}
+	uploadHashes.set(sums.sha256, key)
+
+	return objectURL(cfg, key), nil
+}
+
+func newAWSSession(cfg Config) (*session.Session, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(cfg.Region),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+	return sess, nil
+}
+
+// ObjectURL returns the published URL of a key in the bucket.
+func ObjectURL(key string) string {
+	return objectURL(LoadConfig(), key)
+}
+
+// objectURL returns the published URL of a key: below the configured
+// public base URL, or the bucket's own endpoint.
+func objectURL(cfg Config, key string) string {
+	if cfg.PublicBaseURL != "" {
+		return cfg.PublicBaseURL + "/" + key
+	}
+	return bucketURL(cfg, key)
+}
+
+func bucketURL(cfg Config, key string) string {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", cfg.Bucket, cfg.Region, key)
+}
+
+type fileChecksums struct {
+	size   int64
+	sha256 string
+	digest []byte
+}
+
+// computeFileChecksums streams the file once to get its size and SHA-256.
+func computeFileChecksums(filePath string) (fileChecksums, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fileChecksums{}, fmt.Errorf("failed to read file: %v", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return fileChecksums{}, fmt.Errorf("failed to read file: %v", err)
+	}
+	digest := hash.Sum(nil)
+	return fileChecksums{size: size, sha256: hex.EncodeToString(digest), digest: digest}, nil
+}
+
+func contentTypeFor(key string) string {
+	switch strings.ToLower(filepath.Ext(key)) {
+	case ".png":
+		return "image/png"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".gif":
+		return "image/gif"
+	case ".gpx":
+		return "application/gpx+xml"
+	default:
+		return "image/webp"
+	}
+}
+
+// progressReader reports how much of the body the uploader has consumed.
+type progressReader struct {
+	io.Reader
+	total    int64
+	read     int64
+	progress ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+	if r.progress != nil && r.total > 0 {
+		r.progress(float64(r.read) / float64(r.total))
+	}
+	return n, err
 }
@@ -0,0 +1,437 @@
+package helpers
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// searchFields are the document parts covered by the search index, with
+// the weight a match in them adds to the score.
+var searchFields = []struct {
+	name   string
+	weight int
+}{
+	{"id", 3},
+	{"name", 4},
+	{"type", 1},
+	{"date", 1},
+	{"location", 2},
+	{"description", 1},
+	{"costs", 1},
+	{"equipment", 1},
+	{"transportation", 1},
+	{"accommodation", 1},
+	{"captions", 2},
+	{"tag", 3},
+	{"activity", 2},
+	{"difficulty", 1},
+	{"itinerary", 1},
+	{"person", 2},
+	{"conditions", 1},
+}
+
+const snippetRadius = 40
+
+// SearchResult is a document matching a query.
+type SearchResult struct {
+	Document Document
+	Score    int
+	Snippet  string
+}
+
+type indexedDocument struct {
+	document Document
+	fields   map[string]string // normalized text, padded with spaces
+	raw      map[string]string
+}
+
+// SearchIndex is an in-memory full-text index over published documents.
+type SearchIndex struct {
+	mu        sync.RWMutex
+	documents map[string]*indexedDocument
+	postings  map[string]map[string]bool // term → document paths
+}
+
+var (
+	documentIndex     = &SearchIndex{}
+	documentIndexOnce sync.Once
+)
+
+// DocumentIndex returns the shared index over the output folder, built
+// from disk on first use.
+func DocumentIndex() *SearchIndex {
+	documentIndexOnce.Do(func() {
+		documents, err := LoadDocuments()
+		if err != nil {
+			log.Printf("failed to build search index: %v", err)
+		}
+		documentIndex.Reset(documents)
+	})
+	return documentIndex
+}
+
+// ReindexDocument reads a freshly published file into the shared index.
+func ReindexDocument(path string) error {
+	document, ok, err := LoadDocument(path)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("failed to index %s: the document has no ID", path)
+	}
+	DocumentIndex().Add(document)
+	return nil
+}
+
+// Reset replaces the indexed documents.
+func (ix *SearchIndex) Reset(documents []Document) {
+	ix.mu.Lock()
+	ix.documents = make(map[string]*indexedDocument)
+	ix.postings = make(map[string]map[string]bool)
+	ix.mu.Unlock()
+
+	for _, document := range documents {
+		ix.Add(document)
+	}
+}
+
+// Add indexes a document, replacing an earlier version from the same file.
+func (ix *SearchIndex) Add(document Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.documents == nil {
+		ix.documents = make(map[string]*indexedDocument)
+		ix.postings = make(map[string]map[string]bool)
+	}
+
+	if old, ok := ix.documents[document.Path]; ok {
+		for _, text := range old.fields {
+			for _, term := range strings.Fields(text) {
+				delete(ix.postings[term], document.Path)
+			}
+		}
+	}
+
+	indexed := &indexedDocument{document: document, fields: make(map[string]string), raw: searchableFields(document)}
+	for field, text := range indexed.raw {
+		tokens := tokenize(text)
+		indexed.fields[field] = " " + strings.Join(tokens, " ") + " "
+		for _, term := range tokens {
+			if ix.postings[term] == nil {
+				ix.postings[term] = make(map[string]bool)
+			}
+			ix.postings[term][document.Path] = true
+		}
+	}
+	ix.documents[document.Path] = indexed
+}
+
+// searchableFields extracts the indexed text of a document.
+func searchableFields(document Document) map[string]string {
+	fields := map[string]string{
+		"id":             document.ID,
+		"name":           document.Name(),
+		"type":           document.Type,
+		"date":           document.Dates(),
+		"description":    document.Text("Description"),
+		"costs":          document.Text("Costs"),
+		"equipment":      document.Text("Equipment"),
+		"transportation": document.Text("Transportation"),
+		"accommodation":  document.Text("Accommodation"),
+		"activity":       document.Text("Activity"),
+		"difficulty":     document.Text("Difficulty"),
+	}
+
+	var tags []string
+	items, _ := document.Data["Tags"].([]interface{})
+	for _, item := range items {
+		if tag, ok := item.(string); ok {
+			tags = append(tags, tag)
+		}
+	}
+	fields["tag"] = strings.Join(tags, "\n")
+	fields["person"] = strings.Join(document.People(), "\n")
+	if location, ok := document.Data["Location"].(map[string]interface{}); ok {
+		var parts []string
+		for _, key := range []string{"Name", "Region", "Country"} {
+			if text, _ := location[key].(string); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		fields["location"] = strings.Join(parts, ", ")
+	}
+
+	var captions []string
+	subImages, _ := document.Data["SubImages"].([]interface{})
+	for _, item := range subImages {
+		subImage, _ := item.(map[string]interface{})
+		for _, key := range []string{"Name", "Description", "AltText"} {
+			if text, _ := subImage[key].(string); text != "" {
+				captions = append(captions, text)
+			}
+		}
+	}
+	fields["captions"] = strings.Join(captions, "\n")
+
+	var itinerary []string
+	days, _ := document.Data["Itinerary"].([]interface{})
+	for _, item := range days {
+		day, _ := item.(map[string]interface{})
+		for _, key := range []string{"Title", "StartLocation", "EndLocation"} {
+			if text, _ := day[key].(string); text != "" {
+				itinerary = append(itinerary, text)
+			}
+		}
+	}
+	fields["itinerary"] = strings.Join(itinerary, "\n")
+
+	var conditions []string
+	if reported, ok := document.Data["Conditions"].(map[string]interface{}); ok {
+		for _, key := range []string{"Weather", "SnowCondition", "TrailCondition"} {
+			if text, _ := reported[key].(string); text != "" {
+				conditions = append(conditions, text)
+			}
+		}
+		hazards, _ := reported["Hazards"].([]interface{})
+		for _, item := range hazards {
+			if hazard, ok := item.(string); ok {
+				conditions = append(conditions, hazard)
+			}
+		}
+	}
+	fields["conditions"] = strings.Join(conditions, "\n")
+	return fields
+}
+
+// tokenize lowercases text and splits it into words.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+type searchClause struct {
+	field  string
+	terms  []string
+	prefix bool
+	negate bool
+}
+
+// parseQuery splits a query into clauses. Supported syntax: plain words,
+// "quoted phrases", field:word or field:"phrase" filters, word* prefixes
+// and -word exclusions. A colon after anything but a search field, as in
+// 08:15 or a URL, is part of the text searched for.
+func parseQuery(query string) []searchClause {
+	var clauses []searchClause
+	for _, part := range splitQuery(query) {
+		var clause searchClause
+		if strings.HasPrefix(part, "-") && len(part) > 1 {
+			clause.negate = true
+			part = part[1:]
+		}
+		if field, value, ok := strings.Cut(part, ":"); ok && isSearchField(strings.ToLower(field)) {
+			clause.field = strings.ToLower(field)
+			part = value
+		}
+		part = strings.Trim(part, `"`)
+		if strings.HasSuffix(part, "*") {
+			clause.prefix = true
+		}
+		clause.terms = tokenize(part)
+		if len(clause.terms) > 0 {
+			clauses = append(clauses, clause)
+		}
+	}
+	return clauses
+}
+
+// splitQuery splits on spaces outside of double quotes.
+func splitQuery(query string) []string {
+	var parts []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if current.Len() > 0 {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+func isSearchField(name string) bool {
+	for _, field := range searchFields {
+		if field.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchFieldNames returns the fields usable as field:word filters.
+func SearchFieldNames() []string {
+	names := make([]string, len(searchFields))
+	for i, field := range searchFields {
+		names[i] = field.name
+	}
+	return names
+}
+
+// Documents returns the indexed documents of a type, sorted by ID.
+func (ix *SearchIndex) Documents(docType string) []Document {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	var documents []Document
+	for _, indexed := range ix.documents {
+		if indexed.document.Type == docType {
+			documents = append(documents, indexed.document)
+		}
+	}
+	sort.Slice(documents, func(i, j int) bool { return documents[i].ID < documents[j].ID })
+	return documents
+}
+
+// All returns every indexed document.
+func (ix *SearchIndex) All() []Document {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	documents := make([]Document, 0, len(ix.documents))
+	for _, indexed := range ix.documents {
+		documents = append(documents, indexed.document)
+	}
+	return documents
+}
+
+// Tags aggregates the tags of the indexed documents, most used first.
+func (ix *SearchIndex) Tags() []TagCount {
+	return CountTags(ix.All())
+}
+
+// Search returns the documents matching every clause of the query, best
+// matches first.
+func (ix *SearchIndex) Search(query string) ([]SearchResult, error) {
+	clauses := parseQuery(query)
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	candidates := ix.candidates(clauses)
+	var results []SearchResult
+	for _, path := range candidates {
+		indexed := ix.documents[path]
+		score, snippet, ok := indexed.match(clauses)
+		if ok {
+			results = append(results, SearchResult{Document: indexed.document, Score: score, Snippet: snippet})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Document.ID < results[j].Document.ID
+	})
+	return results, nil
+}
+
+// candidates narrows the documents down with the postings of the first
+// exact term, falling back to all documents.
+func (ix *SearchIndex) candidates(clauses []searchClause) []string {
+	var paths []string
+	for _, clause := range clauses {
+		if clause.negate || clause.prefix {
+			continue
+		}
+		for path := range ix.postings[clause.terms[0]] {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+	for path := range ix.documents {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (d *indexedDocument) match(clauses []searchClause) (int, string, bool) {
+	score := 0
+	snippet := ""
+	for _, clause := range clauses {
+		phrase := " " + strings.Join(clause.terms, " ")
+		if !clause.prefix {
+			phrase += " "
+		}
+
+		matched := false
+		for _, field := range searchFields {
+			if clause.field != "" && clause.field != field.name {
+				continue
+			}
+			count := strings.Count(d.fields[field.name], phrase)
+			if count == 0 {
+				continue
+			}
+			matched = true
+			score += count * field.weight
+			if snippet == "" && !clause.negate && field.name != "type" && field.name != "id" {
+				snippet = makeSnippet(d.raw[field.name], clause.terms[0])
+			}
+		}
+		if matched == clause.negate {
+			return 0, "", false
+		}
+	}
+	return score, snippet, true
+}
+
+// makeSnippet returns the text around the first occurrence of term.
+func makeSnippet(text, term string) string {
+	// Folding rune by rune keeps the offsets valid for the original text;
+	// strings.ToLower can change the number of runes.
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	needle := []rune(term)
+	for i, r := range needle {
+		needle[i] = unicode.ToLower(r)
+	}
+	at := -1
+	for i := 0; i+len(needle) <= len(lower); i++ {
+		if string(lower[i:i+len(needle)]) == string(needle) {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		return ""
+	}
+
+	start, end := max(at-snippetRadius, 0), min(at+len(needle)+snippetRadius, len(runes))
+	snippet := strings.Join(strings.Fields(string(runes[start:end])), " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
@@ -0,0 +1,163 @@
+package helpers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// DraftPrefix is the key prefix under which uploads of unpublished
+// documents without an ID are staged.
+const DraftPrefix = "drafts"
+
+// maxObjectKeyVariants limits the keys tried, the original one included,
+// when a file is moved onto a key that already holds another file.
+const maxObjectKeyVariants = 100
+
+// NewDraftPrefix returns a unique prefix for one editing session.
+func NewDraftPrefix() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return fmt.Sprintf("%s/%s", DraftPrefix, hex.EncodeToString(buf))
+}
+
+// KeyFromURL returns the object key of a URL pointing into the bucket,
+// either through the public base URL or the bucket endpoint.
+func KeyFromURL(rawURL string) (string, bool) {
+	cfg := LoadConfig()
+	for _, base := range []string{objectURL(cfg, ""), bucketURL(cfg, "")} {
+		if !strings.HasPrefix(rawURL, base) {
+			continue
+		}
+		key, err := url.PathUnescape(strings.TrimPrefix(rawURL, base))
+		if err != nil || key == "" {
+			return "", false
+		}
+		return key, true
+	}
+	return "", false
+}
+
+// MoveObject copies an object to a new key and deletes the original,
+// returning the URL of the new object. Headers and metadata are kept; the
+// document ID in metadata and tags is updated to the new key's prefix. An
+// object already at the new key is never overwritten, see freeObjectKey.
+func MoveObject(ctx context.Context, srcKey, dstKey string) (string, error) {
+	cfg := LoadConfig()
+	sess, err := newAWSSession(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	svc := s3.New(sess)
+	head, err := svc.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(cfg.Bucket),
+		Key:    aws.String(srcKey),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", srcKey, err)
+	}
+
+	// Never copy over a published file: an object with the same content is
+	// reused, anything else moves the file to a free key next to it.
+	dstKey, exists, err := freeObjectKey(ctx, svc, cfg.Bucket, dstKey, head)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		if err := deleteObject(ctx, svc, cfg.Bucket, srcKey); err != nil {
+			return "", err
+		}
+		uploadHashes.rename(srcKey, dstKey)
+		return objectURL(cfg, dstKey), nil
+	}
+
+	documentID := strings.SplitN(dstKey, "/", 2)[0]
+	documentType, _ := new(mime.WordDecoder).DecodeHeader(aws.StringValue(head.Metadata[documentTypeMetadataKey]))
+	metadata := head.Metadata
+	if metadata == nil {
+		metadata = make(map[string]*string)
+	}
+	metadata[documentIDMetadataKey] = aws.String(mime.QEncoding.Encode("utf-8", documentID))
+
+	_, err = svc.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+		Bucket:             aws.String(cfg.Bucket),
+		Key:                aws.String(dstKey),
+		CopySource:         aws.String(escapeKey(cfg.Bucket + "/" + srcKey)),
+		MetadataDirective:  aws.String(s3.MetadataDirectiveReplace),
+		ContentType:        head.ContentType,
+		ContentDisposition: head.ContentDisposition,
+		CacheControl:       head.CacheControl,
+		Metadata:           metadata,
+		TaggingDirective:   aws.String(s3.TaggingDirectiveReplace),
+		Tagging:            objectTags(documentID, documentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to copy %s to %s: %v", srcKey, dstKey, err)
+	}
+	if err := deleteObject(ctx, svc, cfg.Bucket, srcKey); err != nil {
+		return "", err
+	}
+
+	uploadHashes.rename(srcKey, dstKey)
+	return objectURL(cfg, dstKey), nil
+}
+
+// freeObjectKey returns dstKey when nothing is stored there, or reports
+// that it already holds the content of src by its hash and size. Otherwise
+// it returns the first free or matching key numbered like "name-2.jpg".
+func freeObjectKey(ctx context.Context, svc *s3.S3, bucket, dstKey string, src *s3.HeadObjectOutput) (string, bool, error) {
+	hash := aws.StringValue(src.Metadata[sha256MetadataKey])
+	extension := path.Ext(dstKey)
+	base := strings.TrimSuffix(dstKey, extension)
+	for n := 1; n <= maxObjectKeyVariants; n++ {
+		key := dstKey
+		if n > 1 {
+			key = fmt.Sprintf("%s-%d%s", base, n, extension)
+		}
+		head, err := svc.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if failure, ok := err.(awserr.RequestFailure); ok && failure.StatusCode() == http.StatusNotFound {
+			return key, false, nil
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read %s: %v", key, err)
+		}
+		if hash != "" && aws.StringValue(head.Metadata[sha256MetadataKey]) == hash &&
+			aws.Int64Value(head.ContentLength) == aws.Int64Value(src.ContentLength) {
+			return key, true, nil
+		}
+	}
+	return "", false, fmt.Errorf("%s and its numbered variants already hold other files", dstKey)
+}
+
+func deleteObject(ctx context.Context, svc *s3.S3, bucket, key string) error {
+	_, err := svc.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %v", key, err)
+	}
+	return nil
+}
+
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -21,6 +21,8 @@ func GetSubImageData(container *fyne.Container) []map[string]string {
 									subImageData["Name"] = entry.Text
 								} else if label.Text == "Sub Image URL:" {
 									subImageData["URL"] = entry.Text
+								} else if label.Text == "Sub Image Alt Text:" {
+									subImageData["AltText"] = entry.Text
 								}
 							}
 						}
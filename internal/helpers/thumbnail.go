@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// maxThumbnailSourceBytes caps how much of an image is downloaded for a
+// thumbnail.
+const maxThumbnailSourceBytes = 20 << 20
+
+// FetchThumbnail downloads an uploaded image and scales it to fit into a
+// size x size square. Files in a private bucket are fetched through a
+// pre-signed URL.
+func FetchThumbnail(ctx context.Context, rawURL string, size int) (image.Image, error) {
+	previewURL, err := PreviewURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, previewURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", rawURL, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+
+	src, _, err := image.Decode(io.LimitReader(resp.Body, maxThumbnailSourceBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", rawURL, err)
+	}
+
+	bounds := src.Bounds()
+	scale := min(float64(size)/float64(bounds.Dx()), float64(size)/float64(bounds.Dy()), 1)
+	dst := image.NewRGBA(image.Rect(0, 0, max(int(float64(bounds.Dx())*scale), 1), max(int(float64(bounds.Dy())*scale), 1)))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst, nil
+}
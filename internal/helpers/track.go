@@ -0,0 +1,60 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type TrackUpload struct {
+	GPXURL              string
+	ElevationProfileURL string
+	Stats               TrackStats
+}
+
+// UploadTrack uploads the GPX file and a rendered elevation profile next to
+// the main image of the document.
+func UploadTrack(ctx context.Context, documentID, gpxPath string, opts UploadOptions, progress ProgressFunc) (TrackUpload, error) {
+	points, err := ParseGPX(gpxPath)
+	if err != nil {
+		return TrackUpload{}, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "elevation-profile")
+	if err != nil {
+		return TrackUpload{}, fmt.Errorf("failed to create temp folder: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	profilePath := filepath.Join(tempDir, "elevation.png")
+	if err := RenderElevationProfile(points, profilePath); err != nil {
+		return TrackUpload{}, err
+	}
+
+	// Each of the two files accounts for half of the reported progress.
+	halfProgress := func(offset float64) ProgressFunc {
+		return func(fraction float64) {
+			if progress != nil {
+				progress(offset + fraction/2)
+			}
+		}
+	}
+	gpxOpts, profileOpts := opts, opts
+	gpxOpts.OriginalFileName = filepath.Base(gpxPath)
+	profileOpts.OriginalFileName = ""
+	gpxURL, err := UploadToS3(ctx, fmt.Sprintf("%s/track.gpx", documentID), gpxPath, gpxOpts, halfProgress(0))
+	if err != nil {
+		return TrackUpload{}, err
+	}
+	profileURL, err := UploadToS3(ctx, fmt.Sprintf("%s/elevation.png", documentID), profilePath, profileOpts, halfProgress(0.5))
+	if err != nil {
+		return TrackUpload{}, err
+	}
+
+	return TrackUpload{
+		GPXURL:              gpxURL,
+		ElevationProfileURL: profileURL,
+		Stats:               ComputeTrackStats(points),
+	}, nil
+}
@@ -0,0 +1,184 @@
+package helpers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// TransportModes are suggested for transport legs; any other text is
+// accepted.
+var TransportModes = []string{"Train", "Bus", "Car", "Ferry", "Flight", "Cable Car", "Taxi", "Bike", "On foot"}
+
+const (
+	legTimeLayout     = "2006-01-02 15:04"
+	legClockLayout    = "15:04"
+	transportDayLimit = 72 * time.Hour
+)
+
+// TransportLeg is one stage of the journey. Departure and Arrival are
+// "2006-01-02 15:04", or just "15:04" when the date does not matter.
+type TransportLeg struct {
+	Mode            string
+	From            string
+	To              string
+	Departure       string
+	Arrival         string
+	DurationMinutes int
+	Operator        string
+	Cost            float64
+	Currency        string
+	BookingURL      string
+}
+
+// parseLegTime reads a departure or arrival time. dated is false for a
+// bare clock time.
+func parseLegTime(text string) (t time.Time, dated bool, err error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if clock, err := time.Parse(legClockLayout, text); err == nil {
+		return clock, false, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout+" "+legClockLayout, text); err == nil {
+			return t, true, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid time %q, use e.g. 2024-07-18 08:15 or 08:15", text)
+}
+
+// NewTransportLeg validates the times and booking link of a leg,
+// normalizes the times and computes its duration. A bare arrival time
+// before the departure is taken to be on the next day.
+func NewTransportLeg(leg TransportLeg) (TransportLeg, error) {
+	if leg.BookingURL != "" && !IsWebURL(leg.BookingURL) {
+		return leg, fmt.Errorf("the booking link of %s – %s must start with http:// or https://", leg.From, leg.To)
+	}
+	if strings.TrimSpace(leg.Departure) == "" || strings.TrimSpace(leg.Arrival) == "" {
+		leg.DurationMinutes = 0
+		return leg, nil
+	}
+	departure, departureDated, err := parseLegTime(leg.Departure)
+	if err != nil {
+		return leg, err
+	}
+	arrival, arrivalDated, err := parseLegTime(leg.Arrival)
+	if err != nil {
+		return leg, err
+	}
+
+	switch {
+	case departureDated && !arrivalDated:
+		arrival = time.Date(departure.Year(), departure.Month(), departure.Day(), arrival.Hour(), arrival.Minute(), 0, 0, time.UTC)
+		if arrival.Before(departure) {
+			arrival = arrival.AddDate(0, 0, 1)
+		}
+	case !departureDated && arrivalDated:
+		return leg, fmt.Errorf("give the departure date of %s – %s as well", leg.From, leg.To)
+	case !departureDated && arrival.Before(departure):
+		arrival = arrival.AddDate(0, 0, 1)
+	}
+	duration := arrival.Sub(departure)
+	if duration < 0 {
+		return leg, fmt.Errorf("%s – %s arrives before it departs", leg.From, leg.To)
+	}
+	if duration > transportDayLimit {
+		return leg, fmt.Errorf("%s – %s takes more than %d hours, check the dates", leg.From, leg.To, int(transportDayLimit.Hours()))
+	}
+
+	if departureDated {
+		leg.Departure = departure.Format(legTimeLayout)
+		leg.Arrival = arrival.Format(legTimeLayout)
+	} else {
+		leg.Departure = departure.Format(legClockLayout)
+		leg.Arrival = arrival.Format(legClockLayout)
+	}
+	leg.DurationMinutes = int(duration.Minutes())
+	return leg, nil
+}
+
+// TravelMinutes adds up the duration of all legs.
+func TravelMinutes(legs []TransportLeg) int {
+	total := 0
+	for _, leg := range legs {
+		total += leg.DurationMinutes
+	}
+	return total
+}
+
+// legDetails lists the operator, duration and cost of a leg.
+func legDetails(leg TransportLeg) []string {
+	var details []string
+	if leg.Operator != "" {
+		details = append(details, leg.Operator)
+	}
+	if leg.DurationMinutes > 0 {
+		details = append(details, FormatDuration(leg.DurationMinutes))
+	}
+	if leg.Cost > 0 {
+		details = append(details, FormatAmount(leg.Cost, leg.Currency))
+	}
+	return details
+}
+
+func legTimes(leg TransportLeg) string {
+	if leg.Departure == "" || leg.Arrival == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s → %s", leg.Departure, leg.Arrival)
+}
+
+// RenderTransportMarkdown renders the legs as a numbered markdown list with
+// the total travel time, followed by the free-text notes, for consumers
+// that only read the Transportation text.
+func RenderTransportMarkdown(legs []TransportLeg, notes string) string {
+	var md strings.Builder
+	for i, leg := range legs {
+		fmt.Fprintf(&md, "%d. **%s**: %s – %s", i+1, leg.Mode, leg.From, leg.To)
+		if times := legTimes(leg); times != "" {
+			fmt.Fprintf(&md, ", %s", times)
+		}
+		if details := legDetails(leg); len(details) > 0 {
+			fmt.Fprintf(&md, " (%s)", strings.Join(details, ", "))
+		}
+		if IsWebURL(leg.BookingURL) {
+			fmt.Fprintf(&md, " [Booking](%s)", leg.BookingURL)
+		}
+		md.WriteString("\n")
+	}
+	if total := TravelMinutes(legs); total > 0 {
+		fmt.Fprintf(&md, "\n**Travel time:** %s\n", FormatDuration(total))
+	}
+	if notes = strings.TrimSpace(notes); notes != "" {
+		fmt.Fprintf(&md, "\n%s\n", notes)
+	}
+	return md.String()
+}
+
+// RenderTransportHTML renders the legs as an ordered list the website can
+// style as a timeline.
+func RenderTransportHTML(legs []TransportLeg) string {
+	var out strings.Builder
+	out.WriteString(`<ol class="transport-timeline">`)
+	for _, leg := range legs {
+		out.WriteString(`<li class="transport-leg">`)
+		fmt.Fprintf(&out, `<span class="transport-mode">%s</span> `, html.EscapeString(leg.Mode))
+		fmt.Fprintf(&out, `<span class="transport-route">%s – %s</span>`, html.EscapeString(leg.From), html.EscapeString(leg.To))
+		if leg.Departure != "" && leg.Arrival != "" {
+			fmt.Fprintf(&out, ` <time datetime="%s">%s</time> → <time datetime="%s">%s</time>`,
+				html.EscapeString(leg.Departure), html.EscapeString(leg.Departure), html.EscapeString(leg.Arrival), html.EscapeString(leg.Arrival))
+		}
+		if details := legDetails(leg); len(details) > 0 {
+			fmt.Fprintf(&out, ` <span class="transport-details">%s</span>`, html.EscapeString(strings.Join(details, ", ")))
+		}
+		if IsWebURL(leg.BookingURL) {
+			fmt.Fprintf(&out, ` <a href="%s">Booking</a>`, html.EscapeString(leg.BookingURL))
+		}
+		out.WriteString(`</li>`)
+	}
+	out.WriteString(`</ol>`)
+	if total := TravelMinutes(legs); total > 0 {
+		fmt.Fprintf(&out, `<p class="transport-total">Travel time: %s</p>`, html.EscapeString(FormatDuration(total)))
+	}
+	return out.String()
+}
@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ObjectKeyField returns the name of the field storing the object key next
+// to a URL field: "MainImagePath" → "MainImageKey", "URL" → "Key".
+func ObjectKeyField(field string) string {
+	for _, suffix := range []string{"Path", "URL"} {
+		if strings.HasSuffix(field, suffix) {
+			return strings.TrimSuffix(field, suffix) + "Key"
+		}
+	}
+	return field + "Key"
+}
+
+// IsWebURL reports whether the text is an absolute http or https URL, the
+// only links published documents may point to.
+func IsWebURL(text string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(text))
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// objectKey returns the key of a URL under the configured bases or one of
+// the extra bases.
+func objectKey(rawURL string, extraBases []string) (string, bool) {
+	if key, ok := KeyFromURL(rawURL); ok {
+		return key, true
+	}
+	for _, base := range extraBases {
+		base = strings.TrimSuffix(base, "/") + "/"
+		if !strings.HasPrefix(rawURL, base) {
+			continue
+		}
+		key, err := url.PathUnescape(strings.TrimPrefix(rawURL, base))
+		if err != nil || key == "" {
+			return "", false
+		}
+		return key, true
+	}
+	return "", false
+}
+
+// RewriteObjectURLs stores the object key next to every URL field pointing
+// into the bucket (or below one of the extra bases) and rebuilds the URL
+// from the key with the configured base. It returns the number of fields
+// whose value changed.
+func RewriteObjectURLs(value interface{}, extraBases []string) int {
+	cfg := LoadConfig()
+	changed := 0
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, item := range v {
+			text, ok := item.(string)
+			if !ok {
+				changed += RewriteObjectURLs(item, extraBases)
+				continue
+			}
+			key, ok := objectKey(text, extraBases)
+			if !ok {
+				continue
+			}
+			keyField := ObjectKeyField(field)
+			if rebuilt := objectURL(cfg, key); rebuilt != text {
+				v[field] = rebuilt
+				changed++
+			}
+			if v[keyField] != key {
+				v[keyField] = key
+				changed++
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			changed += RewriteObjectURLs(item, extraBases)
+		}
+	}
+	return changed
+}
+
+// MarshalDocument renders a document for the output folder, with object
+// keys stored next to their URLs and the URLs built from the configuration.
+func MarshalDocument(data map[string]interface{}) ([]byte, error) {
+	document, err := genericDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	RewriteObjectURLs(document, nil)
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// genericDocument converts document data built from typed values into the
+// form it has when read back from JSON.
+func genericDocument(data map[string]interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %v", err)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, fmt.Errorf("failed to encode document: %v", err)
+	}
+	return document, nil
+}
@@ -0,0 +1,192 @@
+package tabs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+type accommodationRow struct {
+	name       *widget.Entry
+	kind       *widget.SelectEntry
+	address    *widget.Entry
+	latitude   *widget.Entry
+	longitude  *widget.Entry
+	bookingURL *widget.Entry
+	nights     *widget.Entry
+	cost       *widget.Entry
+	currency   *widget.SelectEntry
+	content    fyne.CanvasObject
+}
+
+// accommodationSection edits the places a trip stays at. Their names are
+// offered as the accommodation of itinerary days.
+type accommodationSection struct {
+	currencies []string
+	rows       []*accommodationRow
+	container  *fyne.Container
+	content    fyne.CanvasObject
+
+	// onNamesChanged is called with the record names whenever they change.
+	onNamesChanged func(names []string)
+}
+
+func newAccommodationSection(onNamesChanged func(names []string)) *accommodationSection {
+	s := &accommodationSection{
+		container:      container.NewVBox(),
+		onNamesChanged: onNamesChanged,
+	}
+	if rates, err := helpers.LoadExchangeRates(); err == nil {
+		s.currencies = rates.Currencies()
+	}
+
+	addButton := widget.NewButton("Add Accommodation", func() {
+		s.addRow(helpers.Accommodation{})
+	})
+	s.content = container.NewVBox(s.container, addButton)
+	return s
+}
+
+func (s *accommodationSection) addRow(record helpers.Accommodation) {
+	row := &accommodationRow{
+		name:       widget.NewEntry(),
+		kind:       widget.NewSelectEntry(helpers.AccommodationTypes),
+		address:    widget.NewEntry(),
+		latitude:   widget.NewEntry(),
+		longitude:  widget.NewEntry(),
+		bookingURL: widget.NewEntry(),
+		nights:     widget.NewEntry(),
+		cost:       widget.NewEntry(),
+		currency:   widget.NewSelectEntry(s.currencies),
+	}
+	row.name.SetPlaceHolder("Name")
+	row.kind.SetPlaceHolder("Type")
+	row.address.SetPlaceHolder("Address")
+	row.latitude.SetPlaceHolder("Latitude")
+	row.longitude.SetPlaceHolder("Longitude")
+	row.bookingURL.SetPlaceHolder("Booking URL")
+	row.nights.SetPlaceHolder("Nights (from itinerary)")
+	row.cost.SetPlaceHolder("Cost of the stay")
+	row.currency.SetPlaceHolder("Currency")
+
+	row.name.SetText(record.Name)
+	row.kind.SetText(record.Type)
+	row.address.SetText(record.Address)
+	if record.Latitude != nil && record.Longitude != nil {
+		row.latitude.SetText(strconv.FormatFloat(*record.Latitude, 'f', -1, 64))
+		row.longitude.SetText(strconv.FormatFloat(*record.Longitude, 'f', -1, 64))
+	}
+	row.bookingURL.SetText(record.BookingURL)
+	if record.Nights > 0 {
+		row.nights.SetText(strconv.Itoa(record.Nights))
+	}
+	if record.Cost > 0 {
+		row.cost.SetText(strconv.FormatFloat(record.Cost, 'f', 2, 64))
+	}
+	row.currency.SetText(record.Currency)
+	if record.Currency == "" && len(s.currencies) > 0 {
+		row.currency.SetText(s.currencies[0])
+	}
+	row.name.OnChanged = func(string) { s.namesChanged() }
+
+	removeButton := widget.NewButton("Remove", func() {
+		for i, r := range s.rows {
+			if r == row {
+				s.rows = append(s.rows[:i], s.rows[i+1:]...)
+				break
+			}
+		}
+		s.container.Remove(row.content)
+		s.namesChanged()
+	})
+	row.content = container.NewVBox(
+		container.NewBorder(nil, nil, nil, removeButton, container.NewGridWithColumns(2, row.name, row.kind)),
+		row.address,
+		container.NewGridWithColumns(2, row.latitude, row.longitude),
+		row.bookingURL,
+		container.NewGridWithColumns(3, row.nights, row.cost, row.currency),
+		widget.NewSeparator(),
+	)
+	s.container.Add(row.content)
+	s.rows = append(s.rows, row)
+	s.namesChanged()
+}
+
+func (s *accommodationSection) names() []string {
+	var names []string
+	for _, row := range s.rows {
+		if name := strings.TrimSpace(row.name.Text); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (s *accommodationSection) namesChanged() {
+	if s.onNamesChanged != nil {
+		s.onNamesChanged(s.names())
+	}
+}
+
+// values validates the rows and returns the accommodation records. Rows
+// without a name are skipped.
+func (s *accommodationSection) values() ([]helpers.Accommodation, error) {
+	var records []helpers.Accommodation
+	for _, row := range s.rows {
+		name := strings.TrimSpace(row.name.Text)
+		if name == "" {
+			continue
+		}
+		record := helpers.Accommodation{
+			Name:       name,
+			Type:       strings.TrimSpace(row.kind.Text),
+			Address:    strings.TrimSpace(row.address.Text),
+			BookingURL: strings.TrimSpace(row.bookingURL.Text),
+			Currency:   strings.ToUpper(strings.TrimSpace(row.currency.Text)),
+		}
+
+		if record.BookingURL != "" && !helpers.IsWebURL(record.BookingURL) {
+			return nil, fmt.Errorf("accommodation %q: the booking link must start with http:// or https://", name)
+		}
+		coordinates, err := helpers.NewLocation("", "", "", row.latitude.Text, row.longitude.Text)
+		if err != nil {
+			return nil, fmt.Errorf("accommodation %q: %v", name, err)
+		}
+		if coordinates != nil {
+			record.Latitude, record.Longitude = coordinates.Latitude, coordinates.Longitude
+		}
+		if text := strings.TrimSpace(row.nights.Text); text != "" {
+			if record.Nights, err = strconv.Atoi(text); err != nil || record.Nights < 0 {
+				return nil, fmt.Errorf("accommodation %q: invalid number of nights %q", name, text)
+			}
+		}
+		if text := strings.TrimSpace(row.cost.Text); text != "" {
+			if record.Cost, err = helpers.ParseAmount(text); err != nil {
+				return nil, fmt.Errorf("accommodation %q: %v", name, err)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// load replaces the rows with the records of a published trip and reports
+// whether it had any.
+func (s *accommodationSection) load(document helpers.Document) bool {
+	var records []helpers.Accommodation
+	decodeField(document, "Accommodations", &records)
+
+	s.container.RemoveAll()
+	s.rows = nil
+	for _, record := range records {
+		s.addRow(record)
+	}
+	s.namesChanged()
+	return len(records) > 0
+}
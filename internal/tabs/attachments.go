@@ -0,0 +1,166 @@
+package tabs
+
+import (
+	"context"
+	"fmt"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+type attachmentRow struct {
+	name    *widget.Entry
+	url     *widget.Entry
+	info    *widget.Label
+	content fyne.CanvasObject
+
+	// Set once the upload has finished.
+	attachment *helpers.Attachment
+}
+
+// attachmentSection holds the video, PDF and GPX files of a document.
+type attachmentSection struct {
+	window    fyne.Window
+	prefix    *uploadPrefix
+	uploads   *uploadManager
+	rows      []*attachmentRow
+	container *fyne.Container
+	content   fyne.CanvasObject
+}
+
+func newAttachmentSection(window fyne.Window, prefix *uploadPrefix, uploads *uploadManager) *attachmentSection {
+	s := &attachmentSection{
+		window:    window,
+		prefix:    prefix,
+		uploads:   uploads,
+		container: container.NewVBox(),
+	}
+
+	addButton := widget.NewButton("Add Attachment", func() {
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+			s.add(reader.URI())
+		}, window)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter(helpers.AttachmentExtensions()))
+		fileDialog.Show()
+	})
+
+	s.content = container.NewVBox(s.container, addButton)
+	return s
+}
+
+// add creates a row for the file and queues its upload.
+func (s *attachmentSection) add(file fyne.URI) {
+	row := s.addRow(nameFromFileName(file.Name(), file.Extension()))
+	row.info.SetText("Waiting for upload")
+
+	prefix := s.prefix.next()
+	uploadOptions := s.prefix.options()
+	filePath := file.Path()
+	s.uploads.start(file.Name(), func(ctx context.Context, progress helpers.ProgressFunc) error {
+		key, err := helpers.AttachmentKey(prefix, filePath)
+		if err != nil {
+			onMain(func() { row.info.SetText("Upload failed") })
+			return err
+		}
+		attachment, err := helpers.UploadAttachment(ctx, key, filePath, uploadOptions, progress)
+		if err != nil {
+			onMain(func() { row.info.SetText("Upload failed") })
+			return err
+		}
+		onMain(func() { row.setAttachment(attachment) })
+		return nil
+	})
+}
+
+func (s *attachmentSection) addRow(name string) *attachmentRow {
+	row := &attachmentRow{
+		name: widget.NewEntry(),
+		url:  widget.NewEntry(),
+		info: widget.NewLabel(""),
+	}
+	row.name.SetText(name)
+
+	removeButton := widget.NewButton("Remove", func() {
+		s.remove(row)
+	})
+	row.content = container.NewVBox(
+		widget.NewLabel("Attachment Name:"), row.name,
+		widget.NewLabel("Attachment URL:"), row.url,
+		container.NewBorder(nil, nil, nil, container.NewHBox(newPreviewButton(s.window, row.url), removeButton), row.info),
+	)
+	s.container.Add(row.content)
+	s.rows = append(s.rows, row)
+	return row
+}
+
+func (row *attachmentRow) setAttachment(attachment helpers.Attachment) {
+	row.attachment = &attachment
+	row.url.SetText(attachment.URL)
+	row.info.SetText(fmt.Sprintf("%s, %s", attachment.ContentType, formatSize(attachment.Size)))
+}
+
+// load replaces the rows with the attachments of a published document.
+func (s *attachmentSection) load(attachments []helpers.Attachment) {
+	s.container.RemoveAll()
+	s.rows = nil
+	for _, attachment := range attachments {
+		s.addRow(attachment.Name).setAttachment(attachment)
+	}
+}
+
+func (s *attachmentSection) remove(row *attachmentRow) {
+	for i, r := range s.rows {
+		if r == row {
+			s.rows = append(s.rows[:i], s.rows[i+1:]...)
+			break
+		}
+	}
+	s.container.Remove(row.content)
+}
+
+// urlFields returns the URL entries of all rows.
+func (s *attachmentSection) urlFields() []*widget.Entry {
+	fields := make([]*widget.Entry, len(s.rows))
+	for i, row := range s.rows {
+		fields[i] = row.url
+	}
+	return fields
+}
+
+// attachments returns the uploaded attachments for the document JSON.
+func (s *attachmentSection) attachments() []helpers.Attachment {
+	var attachments []helpers.Attachment
+	for _, row := range s.rows {
+		if row.attachment == nil || row.url.Text == "" {
+			continue
+		}
+		attachment := *row.attachment
+		attachment.Name = row.name.Text
+		attachment.URL = row.url.Text
+		attachments = append(attachments, attachment)
+	}
+	return attachments
+}
+
+// formatSize renders a byte count as "1.4 MB".
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, suffix := float64(size)/unit, 0
+	for value >= unit && suffix < 3 {
+		value /= unit
+		suffix++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "KMGT"[suffix])
+}
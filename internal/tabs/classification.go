@@ -0,0 +1,152 @@
+package tabs
+
+import (
+	"strconv"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const maxTagSuggestions = 10
+
+// classificationSection edits the activity, difficulty, duration, distance
+// and tags of events and trips.
+type classificationSection struct {
+	activity   *widget.Select
+	difficulty *widget.SelectEntry
+	duration   *widget.Entry
+	distance   *widget.Entry
+	tagInput   *widget.SelectEntry
+	tagChips   *fyne.Container
+	tags       []string
+	content    fyne.CanvasObject
+}
+
+func newClassificationSection() *classificationSection {
+	c := &classificationSection{
+		difficulty: widget.NewSelectEntry(nil),
+		duration:   widget.NewEntry(),
+		distance:   widget.NewEntry(),
+		tagInput:   widget.NewSelectEntry(nil),
+		tagChips:   container.NewGridWithColumns(4),
+	}
+	c.activity = widget.NewSelect(helpers.Activities, func(activity string) {
+		c.difficulty.SetOptions(helpers.DifficultyGrades(activity))
+	})
+	c.difficulty.SetPlaceHolder("Grade, e.g. T3")
+	c.duration.SetPlaceHolder("e.g. 5h 30m")
+	c.distance.SetPlaceHolder("Kilometres")
+	c.tagInput.SetPlaceHolder("Type a tag and press Enter")
+	c.tagInput.OnChanged = c.suggestTags
+	c.tagInput.OnSubmitted = func(string) { c.addTagFromInput() }
+	addTagButton := widget.NewButton("Add Tag", c.addTagFromInput)
+
+	c.content = container.NewVBox(
+		widget.NewLabel("Activity:"), c.activity,
+		widget.NewLabel("Difficulty:"), c.difficulty,
+		widget.NewLabel("Duration:"), c.duration,
+		widget.NewLabel("Distance (km):"), c.distance,
+		widget.NewLabel("Tags:"), container.NewBorder(nil, nil, nil, addTagButton, c.tagInput),
+		c.tagChips,
+	)
+	c.suggestTags("")
+	return c
+}
+
+// suggestTags offers the most used existing tags containing the input.
+func (c *classificationSection) suggestTags(input string) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	var suggestions []string
+	for _, count := range helpers.DocumentIndex().Tags() {
+		if len(suggestions) == maxTagSuggestions {
+			break
+		}
+		if strings.Contains(strings.ToLower(count.Tag), input) && !c.hasTag(count.Tag) {
+			suggestions = append(suggestions, count.Tag)
+		}
+	}
+	c.tagInput.SetOptions(suggestions)
+}
+
+func (c *classificationSection) hasTag(tag string) bool {
+	for _, existing := range c.tags {
+		if strings.EqualFold(existing, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *classificationSection) addTagFromInput() {
+	c.setTags(append(c.tags, c.tagInput.Text))
+	c.tagInput.SetText("")
+}
+
+func (c *classificationSection) setTags(tags []string) {
+	c.tags = helpers.NormalizeTags(tags)
+	c.tagChips.RemoveAll()
+	for _, tag := range c.tags {
+		tag := tag
+		c.tagChips.Add(widget.NewButtonWithIcon(tag, theme.CancelIcon(), func() {
+			var remaining []string
+			for _, existing := range c.tags {
+				if existing != tag {
+					remaining = append(remaining, existing)
+				}
+			}
+			c.setTags(remaining)
+		}))
+	}
+}
+
+// suggestDistance fills in the distance of an attached GPX track unless
+// one was entered.
+func (c *classificationSection) suggestDistance(distanceKm float64) {
+	if strings.TrimSpace(c.distance.Text) == "" && distanceKm > 0 {
+		c.distance.SetText(strconv.FormatFloat(distanceKm, 'f', 1, 64))
+	}
+}
+
+// values validates and returns the classification for the document JSON.
+func (c *classificationSection) values() (helpers.Classification, error) {
+	durationMinutes, err := helpers.ParseDuration(c.duration.Text)
+	if err != nil {
+		return helpers.Classification{}, err
+	}
+	distanceKm, err := helpers.ParseDistance(c.distance.Text)
+	if err != nil {
+		return helpers.Classification{}, err
+	}
+	return helpers.Classification{
+		Tags:            append([]string{}, c.tags...),
+		Activity:        c.activity.Selected,
+		Difficulty:      strings.TrimSpace(c.difficulty.Text),
+		DurationMinutes: durationMinutes,
+		DistanceKm:      distanceKm,
+	}, nil
+}
+
+func (c *classificationSection) load(document helpers.Document) {
+	c.activity.SetSelected(document.Text("Activity"))
+	c.difficulty.SetText(document.Text("Difficulty"))
+
+	var durationMinutes int
+	decodeField(document, "DurationMinutes", &durationMinutes)
+	c.duration.SetText(helpers.FormatDuration(durationMinutes))
+
+	var distanceKm float64
+	decodeField(document, "DistanceKm", &distanceKm)
+	c.distance.SetText("")
+	if distanceKm > 0 {
+		c.distance.SetText(strconv.FormatFloat(distanceKm, 'f', -1, 64))
+	}
+
+	var tags []string
+	decodeField(document, "Tags", &tags)
+	c.setTags(tags)
+}
@@ -0,0 +1,146 @@
+package tabs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// conditionsSection edits the weather and terrain conditions of a report,
+// optionally imported from a weather CSV export.
+type conditionsSection struct {
+	weather        *widget.SelectEntry
+	temperatureMin *widget.Entry
+	temperatureMax *widget.Entry
+	snow           *widget.SelectEntry
+	trail          *widget.SelectEntry
+	hazards        *widget.CheckGroup
+	otherHazards   *widget.Entry
+	content        fyne.CanvasObject
+}
+
+func newConditionsSection(window fyne.Window, date *widget.Entry, location *locationSection) *conditionsSection {
+	c := &conditionsSection{
+		weather:        widget.NewSelectEntry(helpers.WeatherTypes),
+		temperatureMin: widget.NewEntry(),
+		temperatureMax: widget.NewEntry(),
+		snow:           widget.NewSelectEntry(helpers.SnowConditions),
+		trail:          widget.NewSelectEntry(helpers.TrailConditions),
+		hazards:        widget.NewCheckGroup(helpers.HazardTypes, nil),
+		otherHazards:   widget.NewEntry(),
+	}
+	c.weather.SetPlaceHolder("Weather")
+	c.temperatureMin.SetPlaceHolder("Min °C")
+	c.temperatureMax.SetPlaceHolder("Max °C")
+	c.snow.SetPlaceHolder("Snow condition")
+	c.trail.SetPlaceHolder("Trail condition")
+	c.hazards.Horizontal = true
+	c.otherHazards.SetPlaceHolder("Other hazards, comma separated")
+
+	importButton := widget.NewButton("Import from Weather CSV", func() {
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+
+			locations := []string{location.name.Text, location.region.Text}
+			imported, err := helpers.ImportWeather(reader.URI().Path(), date.Text, locations)
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			c.fill(imported)
+		}, window)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+		fileDialog.Show()
+	})
+
+	c.content = container.NewVBox(
+		container.NewBorder(nil, nil, nil, importButton, c.weather),
+		container.NewGridWithColumns(2, c.temperatureMin, c.temperatureMax),
+		container.NewGridWithColumns(2, c.snow, c.trail),
+		widget.NewLabel("Hazards encountered:"), c.hazards, c.otherHazards,
+	)
+	return c
+}
+
+// fill sets the fields given in imported conditions, keeping the others.
+func (c *conditionsSection) fill(conditions helpers.Conditions) {
+	if conditions.Weather != "" {
+		c.weather.SetText(conditions.Weather)
+	}
+	if conditions.TemperatureMinC != nil {
+		c.temperatureMin.SetText(strconv.FormatFloat(*conditions.TemperatureMinC, 'f', -1, 64))
+	}
+	if conditions.TemperatureMaxC != nil {
+		c.temperatureMax.SetText(strconv.FormatFloat(*conditions.TemperatureMaxC, 'f', -1, 64))
+	}
+	if conditions.SnowCondition != "" {
+		c.snow.SetText(conditions.SnowCondition)
+	}
+	if conditions.TrailCondition != "" {
+		c.trail.SetText(conditions.TrailCondition)
+	}
+}
+
+// values validates and returns the conditions, or nil when none were
+// entered.
+func (c *conditionsSection) values() (*helpers.Conditions, error) {
+	conditions := helpers.Conditions{
+		Weather:        strings.TrimSpace(c.weather.Text),
+		SnowCondition:  strings.TrimSpace(c.snow.Text),
+		TrailCondition: strings.TrimSpace(c.trail.Text),
+		Hazards:        helpers.NormalizeNames(append(append([]string{}, c.hazards.Selected...), strings.Split(c.otherHazards.Text, ",")...)),
+	}
+	var err error
+	if conditions.TemperatureMinC, err = helpers.ParseTemperature(c.temperatureMin.Text); err != nil {
+		return nil, err
+	}
+	if conditions.TemperatureMaxC, err = helpers.ParseTemperature(c.temperatureMax.Text); err != nil {
+		return nil, err
+	}
+	if conditions.TemperatureMinC != nil && conditions.TemperatureMaxC != nil && *conditions.TemperatureMinC > *conditions.TemperatureMaxC {
+		return nil, fmt.Errorf("the minimum temperature is above the maximum")
+	}
+	if conditions.IsZero() {
+		return nil, nil
+	}
+	return &conditions, nil
+}
+
+func (c *conditionsSection) load(document helpers.Document) {
+	var conditions helpers.Conditions
+	decodeField(document, "Conditions", &conditions)
+
+	c.weather.SetText(conditions.Weather)
+	c.temperatureMin.SetText("")
+	c.temperatureMax.SetText("")
+	c.snow.SetText(conditions.SnowCondition)
+	c.trail.SetText(conditions.TrailCondition)
+	c.fill(conditions)
+
+	var selected, other []string
+	for _, hazard := range conditions.Hazards {
+		known := false
+		for _, option := range helpers.HazardTypes {
+			if strings.EqualFold(option, hazard) {
+				selected = append(selected, option)
+				known = true
+			}
+		}
+		if !known {
+			other = append(other, hazard)
+		}
+	}
+	c.hazards.SetSelected(selected)
+	c.otherHazards.SetText(strings.Join(other, ", "))
+}
@@ -0,0 +1,198 @@
+package tabs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+type costRow struct {
+	category    *widget.SelectEntry
+	description *widget.Entry
+	amount      *widget.Entry
+	currency    *widget.SelectEntry
+	perPerson   *widget.Check
+	content     fyne.CanvasObject
+}
+
+// costSection edits the optional structured cost table of events and trips
+// and shows its totals converted to one currency.
+type costSection struct {
+	rates        helpers.ExchangeRates
+	ratesErr     error
+	rows         []*costRow
+	container    *fyne.Container
+	currency     *widget.SelectEntry
+	participants *widget.Entry
+	totals       *widget.Label
+	content      fyne.CanvasObject
+}
+
+func newCostSection() *costSection {
+	c := &costSection{
+		container:    container.NewVBox(),
+		participants: widget.NewEntry(),
+		totals:       widget.NewLabel(""),
+	}
+	c.rates, c.ratesErr = helpers.LoadExchangeRates()
+	c.currency = widget.NewSelectEntry(c.rates.Currencies())
+	c.currency.SetText(c.rates.Base)
+	c.currency.OnChanged = func(string) { c.update() }
+	c.participants.SetPlaceHolder("1")
+	c.participants.OnChanged = func(string) { c.update() }
+
+	addButton := widget.NewButton("Add Cost", func() { c.addRow(helpers.CostItem{}) })
+	c.content = container.NewVBox(
+		c.container,
+		addButton,
+		container.NewGridWithColumns(2,
+			container.NewBorder(nil, nil, widget.NewLabel("Total in:"), nil, c.currency),
+			container.NewBorder(nil, nil, widget.NewLabel("Participants:"), nil, c.participants),
+		),
+		c.totals,
+	)
+	c.update()
+	return c
+}
+
+func (c *costSection) addRow(item helpers.CostItem) {
+	row := &costRow{
+		category:    widget.NewSelectEntry(helpers.CostCategories),
+		description: widget.NewEntry(),
+		amount:      widget.NewEntry(),
+		currency:    widget.NewSelectEntry(c.rates.Currencies()),
+		perPerson:   widget.NewCheck("Per person", nil),
+	}
+	row.category.SetPlaceHolder("Category")
+	row.description.SetPlaceHolder("Description")
+	row.amount.SetPlaceHolder("Amount")
+
+	row.category.SetText(item.Category)
+	row.description.SetText(item.Description)
+	if item.Amount != 0 {
+		row.amount.SetText(strconv.FormatFloat(item.Amount, 'f', 2, 64))
+	}
+	row.currency.SetText(item.Currency)
+	if item.Currency == "" {
+		row.currency.SetText(strings.TrimSpace(c.currency.Text))
+	}
+	row.perPerson.SetChecked(item.PerPerson)
+
+	row.description.OnChanged = func(string) { c.update() }
+	row.amount.OnChanged = func(string) { c.update() }
+	row.currency.OnChanged = func(string) { c.update() }
+	row.perPerson.OnChanged = func(bool) { c.update() }
+
+	removeButton := widget.NewButton("Remove", func() {
+		for i, r := range c.rows {
+			if r == row {
+				c.rows = append(c.rows[:i], c.rows[i+1:]...)
+				break
+			}
+		}
+		c.container.Remove(row.content)
+		c.update()
+	})
+	row.content = container.NewBorder(nil, nil, nil, container.NewHBox(row.perPerson, removeButton),
+		container.NewGridWithColumns(4, row.category, row.description, row.amount, row.currency))
+	c.container.Add(row.content)
+	c.rows = append(c.rows, row)
+	c.update()
+}
+
+// update recalculates the totals shown below the table.
+func (c *costSection) update() {
+	breakdown, err := c.values()
+	switch {
+	case err != nil:
+		c.totals.SetText(err.Error())
+	case breakdown == nil:
+		c.totals.SetText("No structured costs, the Costs text is published as is")
+	default:
+		c.totals.SetText(fmt.Sprintf("Total %s, per person %s",
+			helpers.FormatAmount(breakdown.Total, breakdown.Currency), helpers.FormatAmount(breakdown.PerPerson, breakdown.Currency)))
+	}
+}
+
+// values validates the table and returns the calculated breakdown, or nil
+// when no costs were entered. A broken rates.json only matters when a cost
+// has to be converted.
+func (c *costSection) values() (*helpers.CostBreakdown, error) {
+	breakdown := &helpers.CostBreakdown{
+		Currency:     strings.ToUpper(strings.TrimSpace(c.currency.Text)),
+		Participants: 1,
+	}
+	if text := strings.TrimSpace(c.participants.Text); text != "" {
+		participants, err := strconv.Atoi(text)
+		if err != nil || participants < 1 {
+			return nil, fmt.Errorf("invalid number of participants %q", text)
+		}
+		breakdown.Participants = participants
+	}
+
+	for _, row := range c.rows {
+		description := strings.TrimSpace(row.description.Text)
+		if strings.TrimSpace(row.amount.Text) == "" && description == "" {
+			continue
+		}
+		amount, err := helpers.ParseAmount(row.amount.Text)
+		if err != nil {
+			return nil, fmt.Errorf("cost %q: %v", description, err)
+		}
+		currency := strings.ToUpper(strings.TrimSpace(row.currency.Text))
+		if currency == "" {
+			currency = breakdown.Currency
+		}
+		breakdown.Items = append(breakdown.Items, helpers.CostItem{
+			Category:    strings.TrimSpace(row.category.Text),
+			Description: description,
+			Amount:      amount,
+			Currency:    currency,
+			PerPerson:   row.perPerson.Checked,
+		})
+	}
+	if len(breakdown.Items) == 0 {
+		return nil, nil
+	}
+	for _, item := range breakdown.Items {
+		if c.ratesErr != nil && item.Currency != breakdown.Currency {
+			return nil, c.ratesErr
+		}
+	}
+	if err := breakdown.Calculate(c.rates); err != nil {
+		return nil, err
+	}
+	return breakdown, nil
+}
+
+// load replaces the table with the breakdown of a published document and
+// reports whether it had one.
+func (c *costSection) load(document helpers.Document) bool {
+	var breakdown *helpers.CostBreakdown
+	decodeField(document, "CostBreakdown", &breakdown)
+
+	c.container.RemoveAll()
+	c.rows = nil
+	c.currency.SetText(c.rates.Base)
+	c.participants.SetText("")
+	if breakdown == nil {
+		c.update()
+		return false
+	}
+
+	c.currency.SetText(breakdown.Currency)
+	if breakdown.Participants > 1 {
+		c.participants.SetText(strconv.Itoa(breakdown.Participants))
+	}
+	for _, item := range breakdown.Items {
+		c.addRow(item)
+	}
+	c.update()
+	return true
+}
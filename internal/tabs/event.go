@@ -1,10 +1,11 @@
 package tabs
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
@@ -14,11 +15,12 @@ import (
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
-func NewEventTab(window fyne.Window) *container.TabItem {
+func NewEventTab(window fyne.Window) (*container.TabItem, DocumentLoader) {
 	// Input fields with current date
 	currentDate := time.Now().Format("2006-01-02")
 	creationDate := widget.NewEntry()
@@ -32,9 +34,23 @@ func NewEventTab(window fyne.Window) *container.TabItem {
 	eventDate := widget.NewEntry()
 	relatedTripURL := widget.NewEntry()
 	uniqueEventID := widget.NewEntry()
+	prefix := newUploadPrefix(uniqueEventID, "Event")
+	author := newAuthorEntry()
+	participants := newParticipantSection()
 	uniqueReportURL := widget.NewEntry()
 	uniqueKomootURL := widget.NewEntry()
 	mainImagePath := widget.NewEntry()
+	mainImageAltText := widget.NewEntry()
+	mainImageAltText.SetPlaceHolder(fmt.Sprintf("What the photo shows, under %d characters", helpers.MaxAltTextLength))
+	mainImageCaption := widget.NewEntry()
+	location := newLocationSection(window)
+	classification := newClassificationSection()
+	costBreakdown := newCostSection()
+	gear := newGearSection(window)
+	transport := newTransportSection()
+	uploads := newUploadManager(window)
+	prefillFromMetadata := widget.NewCheck("Prefill details from photo metadata", nil)
+	prefillFromMetadata.SetChecked(true)
 
 	// Rich text fields with toolbar
 	createRichTextArea := func(label string) (*widget.RichText, *widget.Entry, *widget.Toolbar) {
@@ -113,107 +129,234 @@ func NewEventTab(window fyne.Window) *container.TabItem {
 			}
 			defer reader.Close()
 
-			uploadPath := fmt.Sprintf("%s/main.webp", uniqueEventID.Text)
-			url, err := helpers.UploadToS3(uploadPath, reader.URI().Path())
-			if err != nil {
-				dialog.ShowError(err, window)
-				return
-			}
+			uploadPath := fmt.Sprintf("%s/main.webp", prefix.next())
+			uploadOptions := prefix.options()
+			uploadOptions.AltText = mainImageAltText.Text
+			filePath := reader.URI().Path()
+			uploads.start("Main image", func(ctx context.Context, progress helpers.ProgressFunc) error {
+				url, metadata, err := helpers.UploadImage(ctx, uploadPath, filePath, uploadOptions, progress)
+				if err != nil {
+					return err
+				}
 
-			mainImagePath.SetText(url)
-			dialog.ShowInformation("Success", "Main image uploaded successfully", window)
+				onMain(func() {
+					mainImagePath.SetText(url)
+					if prefillFromMetadata.Checked {
+						location.fillFromMetadata(metadata)
+					}
+				})
+				return nil
+			})
 		}, window)
 	})
 
-	// Sub images container
-	subImageContainer := container.NewVBox()
-	addSubImageButton := widget.NewButton("Add Sub Image", func() {
-		subImagePath := widget.NewEntry()
-		subImageName := widget.NewEntry()
-		subImageDescription := widget.NewMultiLineEntry()
-		subImageDescription.Wrapping = fyne.TextWrapWord
-
-		subImageUploadButton := widget.NewButton("Upload Sub Image", func() {
-			dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
-				if err != nil || reader == nil {
-					return
-				}
-				defer reader.Close()
+	// GPX track and elevation profile
+	gpxTrackPath := widget.NewEntry()
+	elevationProfilePath := widget.NewEntry()
+	var trackStats *helpers.TrackStats
+	gpxUploadButton := widget.NewButton("Attach GPX Track", func() {
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
 
-				imageIndex := len(subImageContainer.Objects) + 1
-				uploadPath := fmt.Sprintf("%s/subImages/image%d.webp", uniqueEventID.Text, imageIndex)
-				url, err := helpers.UploadToS3(uploadPath, reader.URI().Path())
+			documentID := prefix.next()
+			uploadOptions := prefix.options()
+			filePath := reader.URI().Path()
+			uploads.start("GPX track", func(ctx context.Context, progress helpers.ProgressFunc) error {
+				track, err := helpers.UploadTrack(ctx, documentID, filePath, uploadOptions, progress)
 				if err != nil {
-					dialog.ShowError(err, window)
-					return
+					return err
 				}
 
-				subImagePath.SetText(url)
-				dialog.ShowInformation("Success", "Sub image uploaded successfully", window)
-			}, window)
-		})
+				onMain(func() {
+					gpxTrackPath.SetText(track.GPXURL)
+					elevationProfilePath.SetText(track.ElevationProfileURL)
+					trackStats = &track.Stats
+					classification.suggestDistance(track.Stats.DistanceKm)
+				})
+				return nil
+			})
+		}, window)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".gpx"}))
+		fileDialog.Show()
+	})
 
-		subImageItem := container.NewVBox(
-			widget.NewLabel(fmt.Sprintf("Sub Image %d Description:", len(subImageContainer.Objects)+1)),
-			subImageDescription,
-			widget.NewLabel("Sub Image Name:"),
-			subImageName,
-			widget.NewLabel("Sub Image URL:"),
-			subImagePath,
-			subImageUploadButton,
-		)
+	// Sub images container
+	subImages := newSubImageSection(window, prefix, prefillFromMetadata, location, uploads)
 
-		subImageContainer.Add(subImageItem)
-	})
+	// Video, PDF and GPX attachments
+	attachments := newAttachmentSection(window, prefix, uploads)
 
 	// Publish button
 	publishButton := widget.NewButton("Publish", func() {
+		if uploads.busy() {
+			dialog.ShowError(fmt.Errorf("Please wait for the uploads to finish"), window)
+			return
+		}
+
 		// Validate required fields
-		if eventName.Text == "" || eventDate.Text == "" || uniqueEventID.Text == "" || uniqueKomootURL.Text == "" || descriptionEntry.Text == "" || transportationEntry.Text == "" {
+		if eventName.Text == "" || eventDate.Text == "" || uniqueEventID.Text == "" || uniqueKomootURL.Text == "" || descriptionEntry.Text == "" {
 			dialog.ShowError(fmt.Errorf("Please fill all required fields"), window)
 			return
 		}
 
-		eventData := map[string]interface{}{
-			"CreationDate":    creationDate.Text,
-			"EntryType":       entryType.Text,
-			"EventName":       eventName.Text,
-			"EventDate":       eventDate.Text,
-			"RelatedTripURL":  relatedTripURL.Text,
-			"UniqueEventID":   uniqueEventID.Text,
-			"UniqueReportURL": uniqueReportURL.Text,
-			"UniqueKomootURL": uniqueKomootURL.Text,
-			"MainImagePath":   mainImagePath.Text,
-			"Description":     descriptionEntry.Text,
-			"Costs":           costsEntry.Text,
-			"Transportation":  transportationEntry.Text,
-			"Equipment":       equipmentEntry.Text,
-			"SubImages":       helpers.GetSubImageData(subImageContainer),
-		}
-
-		jsonData, err := json.MarshalIndent(eventData, "", "  ")
+		komootTour, err := helpers.ParseKomoot(uniqueKomootURL.Text)
 		if err != nil {
 			dialog.ShowError(err, window)
 			return
 		}
+		uniqueKomootURL.SetText(komootTour.URL)
 
-		outputFolder := "output/events"
-		if _, err := os.Stat(outputFolder); os.IsNotExist(err) {
-			err = os.Mkdir(outputFolder, os.ModePerm)
-			if err != nil {
-				dialog.ShowError(fmt.Errorf("Failed to create output folder: %v", err), window)
-				return
-			}
+		documentLocation, err := location.location()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+
+		documentClassification, err := classification.values()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+
+		transportLegs, err := transport.values()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		if len(transportLegs) == 0 && transportationEntry.Text == "" {
+			dialog.ShowError(fmt.Errorf("Please fill all required fields"), window)
+			return
+		}
+		transportationMarkdown, transportationHTML := transportationEntry.Text, ""
+		if len(transportLegs) > 0 {
+			transportationMarkdown = helpers.RenderTransportMarkdown(transportLegs, transportationEntry.Text)
+			transportationHTML = helpers.RenderTransportHTML(transportLegs)
 		}
 
-		fileName := filepath.Join(outputFolder, fmt.Sprintf("%s_event.json", uniqueEventID.Text))
-		err = os.WriteFile(fileName, jsonData, 0644)
+		gearList, err := gear.values()
 		if err != nil {
 			dialog.ShowError(err, window)
 			return
 		}
+		equipmentMarkdown := equipmentEntry.Text
+		if gearList != nil {
+			equipmentMarkdown = helpers.RenderGearMarkdown(*gearList, equipmentEntry.Text)
+		}
+
+		documentCosts, err := costBreakdown.values()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		costsMarkdown := costsEntry.Text
+		if documentCosts != nil {
+			costsMarkdown = helpers.RenderCostsMarkdown(*documentCosts, costsEntry.Text)
+		}
+
+		eventData := map[string]interface{}{
+			"CreationDate":         creationDate.Text,
+			"EntryType":            entryType.Text,
+			"EventName":            eventName.Text,
+			"Author":               strings.TrimSpace(author.Text),
+			"Participants":         participants.values(),
+			"EventDate":            eventDate.Text,
+			"RelatedTripURL":       relatedTripURL.Text,
+			"UniqueEventID":        uniqueEventID.Text,
+			"UniqueReportURL":      uniqueReportURL.Text,
+			"UniqueKomootURL":      uniqueKomootURL.Text,
+			"Komoot":               komootTour,
+			"MainImagePath":        mainImagePath.Text,
+			"MainImageAltText":     mainImageAltText.Text,
+			"MainImageCaption":     mainImageCaption.Text,
+			"Location":             documentLocation,
+			"Activity":             documentClassification.Activity,
+			"Difficulty":           documentClassification.Difficulty,
+			"Duration":             helpers.FormatDuration(documentClassification.DurationMinutes),
+			"DurationMinutes":      documentClassification.DurationMinutes,
+			"DistanceKm":           documentClassification.DistanceKm,
+			"Tags":                 documentClassification.Tags,
+			"GPXTrackPath":         gpxTrackPath.Text,
+			"ElevationProfilePath": elevationProfilePath.Text,
+			"TrackStats":           trackStats,
+			"Description":          descriptionEntry.Text,
+			"Costs":                costsMarkdown,
+			"CostNotes":            costsEntry.Text,
+			"CostBreakdown":        documentCosts,
+			"Transportation":       transportationMarkdown,
+			"TransportationNotes":  transportationEntry.Text,
+			"TransportationHTML":   transportationHTML,
+			"TransportLegs":        transportLegs,
+			"TravelTime":           helpers.FormatDuration(helpers.TravelMinutes(transportLegs)),
+			"TravelMinutes":        helpers.TravelMinutes(transportLegs),
+			"Equipment":            equipmentMarkdown,
+			"EquipmentNotes":       equipmentEntry.Text,
+			"Gear":                 gearList,
+			"SubImages":            helpers.GetSubImageData(subImages.container),
+			"Attachments":          attachments.attachments(),
+		}
+
+		// write runs once the files are in place, with their final URLs.
+		write := func() {
+			eventData["MainImagePath"] = mainImagePath.Text
+			eventData["GPXTrackPath"] = gpxTrackPath.Text
+			eventData["ElevationProfilePath"] = elevationProfilePath.Text
+			eventData["SubImages"] = helpers.GetSubImageData(subImages.container)
+			eventData["Attachments"] = attachments.attachments()
+
+			jsonData, err := helpers.MarshalDocument(eventData)
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+
+			outputFolder := "output/events"
+			if _, err := os.Stat(outputFolder); os.IsNotExist(err) {
+				err = os.Mkdir(outputFolder, os.ModePerm)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Failed to create output folder: %v", err), window)
+					return
+				}
+			}
+
+			fileName := filepath.Join(outputFolder, fmt.Sprintf("%s_event.json", uniqueEventID.Text))
+			err = os.WriteFile(fileName, jsonData, 0644)
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			helpers.DocumentPublished(fileName)
+
+			dialog.ShowInformation("Success", fmt.Sprintf("Event saved as %s", fileName), window)
+		}
+
+		// Move files uploaded before the ID was set under the final ID,
+		// only once publishing is confirmed
+		save := func() {
+			stagedFields := append([]*widget.Entry{mainImagePath, gpxTrackPath, elevationProfilePath}, subImages.urlFields()...)
+			stagedFields = append(stagedFields, attachments.urlFields()...)
+			relocate := prefix.relocate(stagedFields...)
+			uploads.start(fmt.Sprintf("Publish %s", uniqueEventID.Text), func(ctx context.Context, progress helpers.ProgressFunc) error {
+				if err := relocate(ctx, progress); err != nil {
+					return err
+				}
+				onMain(write)
+				return nil
+			})
+		}
 
-		dialog.ShowInformation("Success", fmt.Sprintf("Event saved as %s", fileName), window)
+		if warnings := helpers.AccessibilityWarnings(eventData); len(warnings) > 0 {
+			message := fmt.Sprintf("%s\n\nPublish anyway?", strings.Join(warnings, "\n"))
+			dialog.ShowConfirm("Accessibility warnings", message, func(publish bool) {
+				if publish {
+					save()
+				}
+			}, window)
+			return
+		}
+		save()
 	})
 
 	// Layout
@@ -226,16 +369,68 @@ func NewEventTab(window fyne.Window) *container.TabItem {
 		widget.NewLabel("Unique Event ID*:"), uniqueEventID,
 		widget.NewLabel("Unique Report URL:"), uniqueReportURL,
 		widget.NewLabel("Unique Komoot URL*:"), uniqueKomootURL,
-		widget.NewLabel("Main Image:"), container.NewHBox(mainImagePath, mainImageUploadButton),
+		widget.NewLabel("Author:"), author,
+		widget.NewLabel("Participants:"), participants.content,
+		widget.NewLabel("Classification:"), classification.content,
+		widget.NewLabel("Location:"), location.content,
+		prefillFromMetadata,
+		widget.NewLabel("Main Image:"), container.NewHBox(mainImagePath, mainImageUploadButton, newPreviewButton(window, mainImagePath)),
+		widget.NewLabel("Main Image Alt Text:"), mainImageAltText,
+		widget.NewLabel("Main Image Caption:"), mainImageCaption,
+		widget.NewLabel("GPX Track:"), container.NewHBox(gpxTrackPath, gpxUploadButton),
+		widget.NewLabel("Elevation Profile:"), container.NewHBox(elevationProfilePath, newPreviewButton(window, elevationProfilePath)),
 		widget.NewLabel("Description*:"), container.NewBorder(descriptionToolbar, nil, nil, nil, container.NewVBox(descriptionEntry, description)),
 		widget.NewLabel("Costs:"), container.NewBorder(costsToolbar, nil, nil, nil, container.NewVBox(costsEntry, costs)),
+		widget.NewLabel("Cost Breakdown:"), costBreakdown.content,
 		widget.NewLabel("Transportation*:"), container.NewBorder(transportationToolbar, nil, nil, nil, container.NewVBox(transportationEntry, transportation)),
+		widget.NewLabel("Transport Legs:"), transport.content,
 		widget.NewLabel("Equipment:"), container.NewBorder(equipmentToolbar, nil, nil, nil, container.NewVBox(equipmentEntry, equipment)),
-		widget.NewLabel("Sub Images:"), subImageContainer, addSubImageButton,
+		widget.NewLabel("Gear List:"), gear.content,
+		widget.NewLabel("Sub Images:"), subImages.content,
+		widget.NewLabel("Attachments:"), attachments.content,
+		widget.NewLabel("Uploads:"), uploads.content,
 		layout.NewSpacer(),
 		publishButton,
 	)
 
+	// Fill the form with a published event for editing
+	load := func(document helpers.Document) {
+		loadEntries(document, map[string]*widget.Entry{
+			"CreationDate":         creationDate,
+			"EventName":            eventName,
+			"EventDate":            eventDate,
+			"RelatedTripURL":       relatedTripURL,
+			"UniqueEventID":        uniqueEventID,
+			"UniqueReportURL":      uniqueReportURL,
+			"UniqueKomootURL":      uniqueKomootURL,
+			"MainImagePath":        mainImagePath,
+			"MainImageAltText":     mainImageAltText,
+			"MainImageCaption":     mainImageCaption,
+			"GPXTrackPath":         gpxTrackPath,
+			"ElevationProfilePath": elevationProfilePath,
+			"Description":          descriptionEntry,
+			"Costs":                costsEntry,
+			"Transportation":       transportationEntry,
+			"Equipment":            equipmentEntry,
+		})
+		trackStats = nil
+		decodeField(document, "TrackStats", &trackStats)
+
+		classification.load(document)
+		author.SetText(document.Text("Author"))
+		participants.load(document)
+		if transport.load(document) {
+			transportationEntry.SetText(document.Text("TransportationNotes"))
+		}
+		if gear.load(document) {
+			equipmentEntry.SetText(document.Text("EquipmentNotes"))
+		}
+		if costBreakdown.load(document) {
+			costsEntry.SetText(document.Text("CostNotes"))
+		}
+		loadSections(document, prefix, location, subImages, attachments)
+	}
+
 	scrollableContent := container.NewVScroll(content)
-	return container.NewTabItem("Event", scrollableContent)
+	return container.NewTabItem("Event", scrollableContent), load
 }
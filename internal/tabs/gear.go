@@ -0,0 +1,212 @@
+package tabs
+
+import (
+	"fmt"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+type gearRow struct {
+	name     *widget.Entry
+	weight   *widget.Entry
+	optional *widget.Check
+	content  fyne.CanvasObject
+}
+
+// gearSection edits the packing list of events and trips, started from a
+// template and tweaked per document.
+type gearSection struct {
+	window    fyne.Window
+	templates *widget.Select
+	template  string
+	rows      []*gearRow
+	container *fyne.Container
+	weight    *widget.Label
+	content   fyne.CanvasObject
+}
+
+func newGearSection(window fyne.Window) *gearSection {
+	s := &gearSection{
+		window:    window,
+		templates: widget.NewSelect(nil, nil),
+		container: container.NewVBox(),
+		weight:    widget.NewLabel(""),
+	}
+	s.templates.PlaceHolder = "Choose a template"
+	s.reloadTemplates()
+
+	applyButton := widget.NewButton("Apply Template", s.applyTemplate)
+	addButton := widget.NewButton("Add Item", func() {
+		s.addRow(helpers.GearItem{})
+	})
+	saveButton := widget.NewButton("Save as Template", s.saveTemplate)
+	s.content = container.NewVBox(
+		container.NewBorder(nil, nil, nil, applyButton, s.templates),
+		s.container,
+		container.NewHBox(addButton, saveButton),
+		s.weight,
+	)
+	s.update()
+	return s
+}
+
+// reloadTemplates refreshes the template names. A broken templates file is
+// reported when a template is applied.
+func (s *gearSection) reloadTemplates() {
+	templates, _ := helpers.LoadGearTemplates()
+	var names []string
+	for _, template := range templates {
+		names = append(names, template.Name)
+	}
+	s.templates.SetOptions(names)
+}
+
+// applyTemplate adds the items of the chosen template that are not on the
+// list yet.
+func (s *gearSection) applyTemplate() {
+	templates, err := helpers.LoadGearTemplates()
+	if err != nil {
+		dialog.ShowError(err, s.window)
+		return
+	}
+	for _, template := range templates {
+		if template.Name != s.templates.Selected {
+			continue
+		}
+		listed := make(map[string]bool)
+		for _, row := range s.rows {
+			listed[strings.ToLower(strings.TrimSpace(row.name.Text))] = true
+		}
+		for _, item := range template.Items {
+			if !listed[strings.ToLower(item.Name)] {
+				s.addRow(item)
+			}
+		}
+		s.template = template.Name
+		s.update()
+		return
+	}
+}
+
+func (s *gearSection) saveTemplate() {
+	list, err := s.values()
+	if err != nil {
+		dialog.ShowError(err, s.window)
+		return
+	}
+	if list == nil {
+		dialog.ShowError(fmt.Errorf("Add some items before saving a template"), s.window)
+		return
+	}
+
+	name := widget.NewEntry()
+	name.SetText(s.template)
+	dialog.ShowForm("Save Gear Template", "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Name", name),
+	}, func(save bool) {
+		if !save || strings.TrimSpace(name.Text) == "" {
+			return
+		}
+		template := helpers.GearTemplate{Name: strings.TrimSpace(name.Text), Items: list.Items}
+		if err := helpers.SaveGearTemplate(template); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		s.template = template.Name
+		s.reloadTemplates()
+	}, s.window)
+}
+
+func (s *gearSection) addRow(item helpers.GearItem) {
+	row := &gearRow{
+		name:     widget.NewEntry(),
+		weight:   widget.NewEntry(),
+		optional: widget.NewCheck("Optional", nil),
+	}
+	row.name.SetPlaceHolder("Item")
+	row.weight.SetPlaceHolder("Weight, e.g. 350 g")
+	row.name.SetText(item.Name)
+	if item.WeightGrams > 0 {
+		row.weight.SetText(helpers.FormatWeight(item.WeightGrams))
+	}
+	row.optional.SetChecked(item.Optional)
+	row.weight.OnChanged = func(string) { s.update() }
+	row.optional.OnChanged = func(bool) { s.update() }
+
+	removeButton := widget.NewButton("Remove", func() {
+		for i, r := range s.rows {
+			if r == row {
+				s.rows = append(s.rows[:i], s.rows[i+1:]...)
+				break
+			}
+		}
+		s.container.Remove(row.content)
+		s.update()
+	})
+	row.content = container.NewBorder(nil, nil, nil, container.NewHBox(row.optional, removeButton),
+		container.NewGridWithColumns(2, row.name, row.weight))
+	s.container.Add(row.content)
+	s.rows = append(s.rows, row)
+	s.update()
+}
+
+// update shows the pack weight below the list.
+func (s *gearSection) update() {
+	list, err := s.values()
+	switch {
+	case err != nil:
+		s.weight.SetText(err.Error())
+	case list == nil:
+		s.weight.SetText("No gear list, the Equipment text is published as is")
+	default:
+		s.weight.SetText(fmt.Sprintf("Pack weight %s, %s without optional items",
+			helpers.FormatWeight(list.TotalWeightGrams), helpers.FormatWeight(list.RequiredWeightGrams)))
+	}
+}
+
+// values validates the list and returns it with its weights, or nil when
+// no items were entered.
+func (s *gearSection) values() (*helpers.GearList, error) {
+	list := &helpers.GearList{Template: s.template}
+	for _, row := range s.rows {
+		name := strings.TrimSpace(row.name.Text)
+		if name == "" {
+			continue
+		}
+		weight, err := helpers.ParseWeight(row.weight.Text)
+		if err != nil {
+			return nil, fmt.Errorf("gear %q: %v", name, err)
+		}
+		list.Items = append(list.Items, helpers.GearItem{Name: name, WeightGrams: weight, Optional: row.optional.Checked})
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	list.Calculate()
+	return list, nil
+}
+
+// load replaces the list with the gear of a published document and reports
+// whether it had any.
+func (s *gearSection) load(document helpers.Document) bool {
+	var list *helpers.GearList
+	decodeField(document, "Gear", &list)
+
+	s.container.RemoveAll()
+	s.rows = nil
+	s.template = ""
+	if list != nil {
+		s.template = list.Template
+		for _, item := range list.Items {
+			s.addRow(item)
+		}
+	}
+	s.update()
+	return list != nil
+}
@@ -0,0 +1,111 @@
+package tabs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const shortLinkTimeout = 10 * time.Second
+
+// googleMapField edits a Google Maps link. Share links are resolved in the
+// background as soon as they are entered, and links without coordinates are
+// kept as they are with a warning below the field.
+type googleMapField struct {
+	entry   *widget.Entry
+	status  *widget.Label
+	content fyne.CanvasObject
+
+	mu        sync.Mutex
+	resolving string
+}
+
+func newGoogleMapField() *googleMapField {
+	f := &googleMapField{
+		entry:  widget.NewEntry(),
+		status: widget.NewLabel(""),
+	}
+	f.entry.OnChanged = f.changed
+	f.content = container.NewVBox(f.entry, f.status)
+	return f
+}
+
+func (f *googleMapField) changed(text string) {
+	text = strings.TrimSpace(text)
+	if !helpers.IsGoogleMapShortLink(text) {
+		f.mu.Lock()
+		f.resolving = ""
+		f.mu.Unlock()
+	}
+	switch {
+	case text == "":
+		f.status.SetText("")
+	case helpers.IsGoogleMapShortLink(text):
+		f.resolve(text)
+	default:
+		if _, err := helpers.ParseGoogleMap(text); err != nil {
+			f.status.SetText(fmt.Sprintf("%v, the link is published as entered", err))
+		} else {
+			f.status.SetText("")
+		}
+	}
+}
+
+// resolve follows a share link without blocking the window and replaces it
+// with the full link, unless the field was edited in the meantime.
+func (f *googleMapField) resolve(link string) {
+	f.mu.Lock()
+	f.resolving = link
+	f.mu.Unlock()
+	f.status.SetText("Resolving short link…")
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shortLinkTimeout)
+		defer cancel()
+		resolved, err := helpers.ResolveShortLink(ctx, link)
+
+		// The field stays busy until the resolved link is in the entry.
+		onMain(func() {
+			f.mu.Lock()
+			current := f.resolving == link
+			if current {
+				f.resolving = ""
+			}
+			f.mu.Unlock()
+			if !current || strings.TrimSpace(f.entry.Text) != link {
+				return
+			}
+			if err != nil {
+				f.status.SetText(fmt.Sprintf("%v, the link is published as entered", err))
+				return
+			}
+			f.entry.SetText(resolved)
+		})
+	}()
+}
+
+// busy reports whether a share link is still being resolved.
+func (f *googleMapField) busy() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.resolving != ""
+}
+
+// location returns the parsed map link and sets the field to its canonical
+// form, or nil when the link is empty or has no coordinates.
+func (f *googleMapField) location() *helpers.GoogleMapLocation {
+	mapLocation, err := helpers.ParseGoogleMap(f.entry.Text)
+	if err != nil {
+		return nil
+	}
+	f.entry.SetText(mapLocation.URL)
+	return &mapLocation
+}
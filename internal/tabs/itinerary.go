@@ -0,0 +1,229 @@
+package tabs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+type itineraryRow struct {
+	heading       *widget.Label
+	date          *widget.Entry
+	title         *widget.Entry
+	startLocation *widget.Entry
+	endLocation   *widget.Entry
+	distance      *widget.Entry
+	elevationGain *widget.Entry
+	elevationLoss *widget.Entry
+	event         *widget.SelectEntry
+	accommodation *widget.SelectEntry
+	content       fyne.CanvasObject
+}
+
+// itinerarySection edits the day-by-day plan of a trip.
+type itinerarySection struct {
+	window    fyne.Window
+	startDate *widget.Entry
+	endDate   *widget.Entry
+	rows      []*itineraryRow
+	container *fyne.Container
+	content   fyne.CanvasObject
+
+	accommodations []string
+}
+
+func newItinerarySection(window fyne.Window, startDate, endDate *widget.Entry) *itinerarySection {
+	s := &itinerarySection{
+		window:    window,
+		startDate: startDate,
+		endDate:   endDate,
+		container: container.NewVBox(),
+	}
+
+	addButton := widget.NewButton("Add Day", func() {
+		s.addRow(helpers.ItineraryDay{})
+	})
+	generateButton := widget.NewButton("Add Days from Trip Dates", s.generate)
+	s.content = container.NewVBox(s.container, container.NewHBox(addButton, generateButton))
+	return s
+}
+
+func (s *itinerarySection) addRow(day helpers.ItineraryDay) *itineraryRow {
+	row := &itineraryRow{
+		heading:       widget.NewLabel(""),
+		date:          widget.NewEntry(),
+		title:         widget.NewEntry(),
+		startLocation: widget.NewEntry(),
+		endLocation:   widget.NewEntry(),
+		distance:      widget.NewEntry(),
+		elevationGain: widget.NewEntry(),
+		elevationLoss: widget.NewEntry(),
+		event:         widget.NewSelectEntry(nil),
+		accommodation: widget.NewSelectEntry(nil),
+	}
+	row.heading.TextStyle = fyne.TextStyle{Bold: true}
+	row.date.SetPlaceHolder("Date")
+	row.title.SetPlaceHolder("Title")
+	row.startLocation.SetPlaceHolder("Start location")
+	row.endLocation.SetPlaceHolder("End location")
+	row.distance.SetPlaceHolder("Distance (km)")
+	row.elevationGain.SetPlaceHolder("Ascent (m)")
+	row.elevationLoss.SetPlaceHolder("Descent (m)")
+	row.event.SetPlaceHolder("Linked event ID")
+	row.accommodation.SetPlaceHolder("Accommodation")
+
+	var events []string
+	for _, document := range helpers.DocumentIndex().Documents("Event") {
+		events = append(events, document.ID)
+	}
+	row.event.SetOptions(events)
+	row.accommodation.SetOptions(s.accommodations)
+
+	row.date.SetText(day.Date)
+	row.title.SetText(day.Title)
+	row.startLocation.SetText(day.StartLocation)
+	row.endLocation.SetText(day.EndLocation)
+	if day.DistanceKm > 0 {
+		row.distance.SetText(strconv.FormatFloat(day.DistanceKm, 'f', -1, 64))
+	}
+	if day.ElevationGainM > 0 {
+		row.elevationGain.SetText(strconv.Itoa(day.ElevationGainM))
+	}
+	if day.ElevationLossM > 0 {
+		row.elevationLoss.SetText(strconv.Itoa(day.ElevationLossM))
+	}
+	row.event.SetText(day.EventID)
+	row.accommodation.SetText(day.Accommodation)
+
+	removeButton := widget.NewButton("Remove Day", func() {
+		s.remove(row)
+	})
+	row.content = container.NewVBox(
+		container.NewBorder(nil, nil, row.heading, removeButton),
+		container.NewGridWithColumns(2, row.date, row.title),
+		container.NewGridWithColumns(2, row.startLocation, row.endLocation),
+		container.NewGridWithColumns(3, row.distance, row.elevationGain, row.elevationLoss),
+		container.NewGridWithColumns(2, row.event, row.accommodation),
+	)
+	s.container.Add(row.content)
+	s.rows = append(s.rows, row)
+	s.renumber()
+	return row
+}
+
+// setAccommodations offers the names of the trip's accommodation records
+// for every day.
+func (s *itinerarySection) setAccommodations(names []string) {
+	s.accommodations = names
+	for _, row := range s.rows {
+		row.accommodation.SetOptions(names)
+	}
+}
+
+func (s *itinerarySection) remove(row *itineraryRow) {
+	for i, r := range s.rows {
+		if r == row {
+			s.rows = append(s.rows[:i], s.rows[i+1:]...)
+			break
+		}
+	}
+	s.container.Remove(row.content)
+	s.renumber()
+}
+
+func (s *itinerarySection) renumber() {
+	for i, row := range s.rows {
+		row.heading.SetText(fmt.Sprintf("Day %d", i+1))
+	}
+}
+
+// generate adds an empty day for every trip date without one, keeping the
+// days already planned.
+func (s *itinerarySection) generate() {
+	dates, err := helpers.TripDays(s.startDate.Text, s.endDate.Text)
+	if err != nil {
+		dialog.ShowError(err, s.window)
+		return
+	}
+
+	var days []helpers.ItineraryDay
+	planned := make(map[string]bool)
+	for _, row := range s.rows {
+		day := row.day()
+		if date, ok := helpers.ParseDate(day.Date); ok {
+			planned[date.Format("2006-01-02")] = true
+		}
+		days = append(days, day)
+	}
+	for _, date := range dates {
+		parsed, _ := helpers.ParseDate(date)
+		if !planned[parsed.Format("2006-01-02")] {
+			days = append(days, helpers.ItineraryDay{Date: date})
+		}
+	}
+	helpers.SortItinerary(days)
+	s.setDays(days)
+}
+
+// day reads the row without validating the numbers.
+func (row *itineraryRow) day() helpers.ItineraryDay {
+	distanceKm, _ := helpers.ParseDistance(row.distance.Text)
+	elevationGain, _ := helpers.ParseElevation(row.elevationGain.Text)
+	elevationLoss, _ := helpers.ParseElevation(row.elevationLoss.Text)
+	return helpers.ItineraryDay{
+		Date:           strings.TrimSpace(row.date.Text),
+		Title:          strings.TrimSpace(row.title.Text),
+		StartLocation:  strings.TrimSpace(row.startLocation.Text),
+		EndLocation:    strings.TrimSpace(row.endLocation.Text),
+		DistanceKm:     distanceKm,
+		ElevationGainM: elevationGain,
+		ElevationLossM: elevationLoss,
+		EventID:        strings.TrimSpace(row.event.Text),
+		Accommodation:  strings.TrimSpace(row.accommodation.Text),
+	}
+}
+
+func (s *itinerarySection) setDays(days []helpers.ItineraryDay) {
+	s.container.RemoveAll()
+	s.rows = nil
+	for _, day := range days {
+		s.addRow(day)
+	}
+}
+
+// values validates the itinerary against the trip dates and returns the
+// days in date order.
+func (s *itinerarySection) values() ([]helpers.ItineraryDay, error) {
+	var days []helpers.ItineraryDay
+	for i, row := range s.rows {
+		day := row.day()
+		var err error
+		if day.DistanceKm, err = helpers.ParseDistance(row.distance.Text); err != nil {
+			return nil, fmt.Errorf("itinerary day %d: %v", i+1, err)
+		}
+		if day.ElevationGainM, err = helpers.ParseElevation(row.elevationGain.Text); err != nil {
+			return nil, fmt.Errorf("itinerary day %d: %v", i+1, err)
+		}
+		if day.ElevationLossM, err = helpers.ParseElevation(row.elevationLoss.Text); err != nil {
+			return nil, fmt.Errorf("itinerary day %d: %v", i+1, err)
+		}
+		days = append(days, day)
+	}
+	if err := helpers.ValidateItinerary(days, s.startDate.Text, s.endDate.Text); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+func (s *itinerarySection) load(document helpers.Document) {
+	var days []helpers.ItineraryDay
+	decodeField(document, "Itinerary", &days)
+	s.setDays(days)
+}
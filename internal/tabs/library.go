@@ -0,0 +1,342 @@
+package tabs
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	thumbnailSize    = 64
+	thumbnailTimeout = 15 * time.Second
+
+	sortRelevance = "Best match"
+	sortNewest    = "Newest first"
+	sortOldest    = "Oldest first"
+	sortName      = "Name"
+	sortID        = "ID"
+
+	allTypes = "All types"
+)
+
+// Editor is an editing tab that published documents can be opened in.
+type Editor struct {
+	Tab  *container.TabItem
+	Load DocumentLoader
+}
+
+type libraryEntry struct {
+	document   helpers.Document
+	remoteOnly bool
+	name       string
+	dates      string
+	date       time.Time
+	thumbnail  string
+	searchText string // used for documents only found in the bucket
+	score      int
+	snippet    string
+}
+
+func newLibraryEntry(document helpers.Document) libraryEntry {
+	entry := libraryEntry{
+		document:  document,
+		name:      document.Name(),
+		dates:     document.Dates(),
+		thumbnail: document.Text("MainImagePath"),
+	}
+	entry.date, _ = document.Date()
+	return entry
+}
+
+// thumbnailCache downloads each thumbnail once and keeps it in memory.
+type thumbnailCache struct {
+	mu      sync.Mutex
+	images  map[string]image.Image
+	pending map[string]bool
+}
+
+// get returns the cached thumbnail, or starts fetching it and calls done
+// on the UI thread once it is available.
+func (c *thumbnailCache) get(url string, done func()) (image.Image, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if img, ok := c.images[url]; ok {
+		return img, img != nil
+	}
+	if c.pending[url] {
+		return nil, false
+	}
+	c.pending[url] = true
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), thumbnailTimeout)
+		defer cancel()
+		img, err := helpers.FetchThumbnail(ctx, url, thumbnailSize*2)
+		if err != nil {
+			img = nil // cached as missing, so a broken image is not fetched again
+		}
+
+		c.mu.Lock()
+		c.images[url] = img
+		delete(c.pending, url)
+		c.mu.Unlock()
+		onMain(done)
+	}()
+	return nil, false
+}
+
+// NewLibraryTab lists the documents in the output folder, and optionally
+// document IDs that only exist in the bucket. Selecting a document opens
+// it in its editing tab.
+func NewLibraryTab(window fyne.Window, appTabs *container.AppTabs, editors map[string]Editor) *container.TabItem {
+	var (
+		entries  []libraryEntry
+		filtered []libraryEntry
+	)
+	thumbnails := &thumbnailCache{images: make(map[string]image.Image), pending: make(map[string]bool)}
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder(`Search text, "phrases", word*, -word or field:word`)
+	typeFilter := widget.NewSelect([]string{allTypes, "Report", "Event", "Trip"}, nil)
+	typeFilter.SetSelected(allTypes)
+	sortOrder := widget.NewSelect([]string{sortRelevance, sortNewest, sortOldest, sortName, sortID}, nil)
+	sortOrder.SetSelected(sortRelevance)
+	includeBucket := widget.NewCheck("Include documents only found in the bucket", nil)
+	status := widget.NewLabel("")
+
+	// mu guards the entries and the filtered list against the bucket listing
+	// running in the background; listing counts the refreshes so a stale
+	// listing is dropped. The list callbacks read the filtered list under mu.
+	var (
+		mu      sync.Mutex
+		listing int
+	)
+
+	var list *widget.List
+	list = widget.NewList(
+		func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(filtered)
+		},
+		func() fyne.CanvasObject {
+			thumbnail := canvas.NewImageFromImage(nil)
+			thumbnail.FillMode = canvas.ImageFillContain
+			thumbnail.SetMinSize(fyne.NewSize(thumbnailSize, thumbnailSize))
+			title := widget.NewLabel("")
+			title.TextStyle = fyne.TextStyle{Bold: true}
+			return container.NewBorder(nil, nil, thumbnail, nil, container.NewVBox(title, widget.NewLabel("")))
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			mu.Lock()
+			if id >= len(filtered) {
+				mu.Unlock()
+				return
+			}
+			entry := filtered[id]
+			mu.Unlock()
+			row := item.(*fyne.Container)
+			labels := row.Objects[0].(*fyne.Container)
+			thumbnail := row.Objects[1].(*canvas.Image)
+
+			title := entry.document.ID
+			if entry.name != "" {
+				title = fmt.Sprintf("%s – %s", entry.document.ID, entry.name)
+			}
+			labels.Objects[0].(*widget.Label).SetText(title)
+			switch {
+			case entry.remoteOnly:
+				labels.Objects[1].(*widget.Label).SetText("Only in bucket, no JSON in output")
+			case entry.snippet != "":
+				labels.Objects[1].(*widget.Label).SetText(entry.snippet)
+			default:
+				labels.Objects[1].(*widget.Label).SetText(strings.TrimSpace(fmt.Sprintf("%s  %s", entry.document.Type, entry.dates)))
+			}
+
+			thumbnail.Image = nil
+			if entry.thumbnail != "" {
+				if img, ok := thumbnails.get(entry.thumbnail, func() { list.Refresh() }); ok {
+					thumbnail.Image = img
+				}
+			}
+			thumbnail.Refresh()
+		},
+	)
+
+	// applyFilter rebuilds the filtered list under mu and then updates the
+	// widgets, whose callbacks take mu themselves.
+	applyFilter := func() {
+		query := strings.TrimSpace(search.Text)
+		var matches map[string]helpers.SearchResult
+		if query != "" {
+			results, err := helpers.DocumentIndex().Search(query)
+			if err != nil {
+				status.SetText(err.Error())
+				return
+			}
+			matches = make(map[string]helpers.SearchResult, len(results))
+			for _, result := range results {
+				matches[result.Document.Path] = result
+			}
+		}
+
+		mu.Lock()
+		filtered = filtered[:0]
+		for _, entry := range entries {
+			if typeFilter.Selected != allTypes && entry.document.Type != typeFilter.Selected {
+				continue
+			}
+			entry.score, entry.snippet = 0, ""
+			if query != "" {
+				if entry.remoteOnly {
+					if !strings.Contains(entry.searchText, strings.ToLower(query)) {
+						continue
+					}
+				} else {
+					match, ok := matches[entry.document.Path]
+					if !ok {
+						continue
+					}
+					entry.score, entry.snippet = match.Score, match.Snippet
+				}
+			}
+			filtered = append(filtered, entry)
+		}
+
+		sort.SliceStable(filtered, func(i, j int) bool {
+			a, b := filtered[i], filtered[j]
+			switch sortOrder.Selected {
+			case sortRelevance:
+				if a.score != b.score {
+					return a.score > b.score
+				}
+				return a.date.After(b.date)
+			case sortOldest:
+				return a.date.Before(b.date)
+			case sortName:
+				return strings.ToLower(a.name) < strings.ToLower(b.name)
+			case sortID:
+				return a.document.ID < b.document.ID
+			default:
+				return a.date.After(b.date)
+			}
+		})
+
+		counts := fmt.Sprintf("%d of %d documents", len(filtered), len(entries))
+		mu.Unlock()
+
+		status.SetText(counts)
+		list.UnselectAll()
+		list.Refresh()
+	}
+
+	refresh := func() {
+		documents, err := helpers.LoadDocuments()
+		if err != nil {
+			dialog.ShowError(err, window)
+			if documents == nil {
+				return
+			}
+		}
+
+		// Documents that failed to load keep their previous version in the
+		// index until they can be read again.
+		if err == nil {
+			helpers.DocumentIndex().Reset(documents)
+		} else {
+			for _, document := range documents {
+				helpers.DocumentIndex().Add(document)
+			}
+		}
+
+		mu.Lock()
+		entries = entries[:0]
+		local := make(map[string]bool)
+		for _, document := range documents {
+			entries = append(entries, newLibraryEntry(document))
+			local[document.ID] = true
+		}
+		listing++
+		current := listing
+		mu.Unlock()
+		applyFilter()
+
+		if !includeBucket.Checked {
+			return
+		}
+		status.SetText(status.Text + ", listing the bucket…")
+		go func() {
+			prefixes, err := helpers.ListDocumentPrefixes(context.Background())
+			if err != nil {
+				onMain(func() { dialog.ShowError(err, window) })
+			}
+
+			mu.Lock()
+			if current != listing {
+				mu.Unlock()
+				return
+			}
+			for _, id := range prefixes {
+				if !local[id] {
+					entries = append(entries, libraryEntry{
+						document:   helpers.Document{ID: id},
+						remoteOnly: true,
+						thumbnail:  helpers.ObjectURL(id + "/main.webp"),
+						searchText: strings.ToLower(id),
+					})
+				}
+			}
+			mu.Unlock()
+			onMain(applyFilter)
+		}()
+	}
+
+	list.OnSelected = func(id widget.ListItemID) {
+		mu.Lock()
+		if id >= len(filtered) {
+			mu.Unlock()
+			return
+		}
+		entry := filtered[id]
+		mu.Unlock()
+		list.UnselectAll()
+		if entry.remoteOnly {
+			dialog.ShowInformation("Not Published", fmt.Sprintf("%s has uploads in the bucket but no document in output", entry.document.ID), window)
+			return
+		}
+		editor, ok := editors[entry.document.Type]
+		if !ok {
+			return
+		}
+		editor.Load(entry.document)
+		appTabs.Select(editor.Tab)
+	}
+
+	search.OnChanged = func(string) { applyFilter() }
+	typeFilter.OnChanged = func(string) { applyFilter() }
+	sortOrder.OnChanged = func(string) { applyFilter() }
+	includeBucket.OnChanged = func(bool) { refresh() }
+	refreshButton := widget.NewButton("Refresh", refresh)
+
+	refresh()
+
+	controls := container.NewVBox(
+		search,
+		container.NewGridWithColumns(2, typeFilter, sortOrder),
+		container.NewHBox(includeBucket, refreshButton),
+		status,
+	)
+	return container.NewTabItem("Library", container.NewBorder(controls, nil, nil, nil, list))
+}
@@ -0,0 +1,57 @@
+package tabs
+
+import (
+	"encoding/json"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2/widget"
+)
+
+// DocumentLoader fills an editing tab with a published document.
+type DocumentLoader func(document helpers.Document)
+
+// loadEntries sets every entry to the string stored under its JSON key,
+// clearing entries whose key is missing.
+func loadEntries(document helpers.Document, entries map[string]*widget.Entry) {
+	for field, entry := range entries {
+		entry.SetText(document.Text(field))
+	}
+}
+
+// decodeField converts a nested JSON value of the document into target.
+func decodeField(document helpers.Document, field string, target interface{}) bool {
+	value, ok := document.Data[field]
+	if !ok || value == nil {
+		return false
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(raw, target) == nil
+}
+
+// stringField returns a string value of a nested JSON object.
+func stringField(data map[string]interface{}, field string) string {
+	text, _ := data[field].(string)
+	return text
+}
+
+// loadSections fills the location, sub-image and attachment sections shared
+// by all editing tabs, and resets the upload prefix to the document ID.
+func loadSections(document helpers.Document, prefix *uploadPrefix, location *locationSection, subImages *subImageSection, attachments *attachmentSection) {
+	var documentLocation *helpers.Location
+	decodeField(document, "Location", &documentLocation)
+	location.load(documentLocation)
+
+	var subImageData []map[string]interface{}
+	decodeField(document, "SubImages", &subImageData)
+	subImages.load(subImageData)
+
+	var attachmentData []helpers.Attachment
+	decodeField(document, "Attachments", &attachmentData)
+	attachments.load(attachmentData)
+
+	prefix.reset(document.ID)
+}
@@ -0,0 +1,147 @@
+package tabs
+
+import (
+	"fmt"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	defaultMapLatitude  = 47.0
+	defaultMapLongitude = 11.0
+	defaultMapZoomLevel = 6
+	pickedMapZoomLevel  = 12
+)
+
+type locationSection struct {
+	name      *widget.Entry
+	region    *widget.Entry
+	country   *widget.Entry
+	latitude  *widget.Entry
+	longitude *widget.Entry
+	content   fyne.CanvasObject
+}
+
+func newLocationSection(window fyne.Window) *locationSection {
+	l := &locationSection{
+		name:      widget.NewEntry(),
+		region:    widget.NewEntry(),
+		country:   widget.NewEntry(),
+		latitude:  widget.NewEntry(),
+		longitude: widget.NewEntry(),
+	}
+	l.latitude.SetPlaceHolder("Latitude")
+	l.longitude.SetPlaceHolder("Longitude")
+
+	pickButton := widget.NewButton("Pick on Map", func() {
+		l.showMapPicker(window)
+	})
+	photoButton := widget.NewButton("From Photo GPS", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+
+			metadata, err := helpers.ReadImageMetadata(reader.URI().Path())
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			if metadata.Latitude == nil || metadata.Longitude == nil {
+				dialog.ShowInformation("No GPS Data", "The selected photo has no GPS coordinates", window)
+				return
+			}
+			l.setCoordinates(*metadata.Latitude, *metadata.Longitude)
+		}, window)
+	})
+
+	l.content = container.NewVBox(
+		widget.NewLabel("Location Name:"), l.name,
+		widget.NewLabel("Region:"), l.region,
+		widget.NewLabel("Country:"), l.country,
+		widget.NewLabel("Coordinates:"), container.NewGridWithColumns(2, l.latitude, l.longitude),
+		container.NewHBox(pickButton, photoButton),
+	)
+	return l
+}
+
+// load fills the section from a published location, clearing it when the
+// document has none.
+func (l *locationSection) load(location *helpers.Location) {
+	if location == nil {
+		location = &helpers.Location{}
+	}
+	l.name.SetText(location.Name)
+	l.region.SetText(location.Region)
+	l.country.SetText(location.Country)
+	l.latitude.SetText("")
+	l.longitude.SetText("")
+	if location.Latitude != nil && location.Longitude != nil {
+		l.setCoordinates(*location.Latitude, *location.Longitude)
+	}
+}
+
+func (l *locationSection) setCoordinates(lat, lon float64) {
+	l.latitude.SetText(helpers.FormatCoordinate(lat))
+	l.longitude.SetText(helpers.FormatCoordinate(lon))
+}
+
+// fillFromMetadata copies the GPS position of an uploaded photo into the
+// location unless coordinates were already entered.
+func (l *locationSection) fillFromMetadata(metadata helpers.ImageMetadata) {
+	if l.latitude.Text != "" || l.longitude.Text != "" {
+		return
+	}
+	if metadata.Latitude == nil || metadata.Longitude == nil {
+		return
+	}
+	l.setCoordinates(*metadata.Latitude, *metadata.Longitude)
+}
+
+func (l *locationSection) location() (*helpers.Location, error) {
+	return helpers.NewLocation(l.name.Text, l.region.Text, l.country.Text, l.latitude.Text, l.longitude.Text)
+}
+
+func (l *locationSection) showMapPicker(window fyne.Window) {
+	lat, lon, zoom := defaultMapLatitude, defaultMapLongitude, defaultMapZoomLevel
+	var picked *[2]float64
+	if current, err := l.location(); err == nil && current != nil && current.Latitude != nil {
+		lat, lon, zoom = *current.Latitude, *current.Longitude, pickedMapZoomLevel
+		picked = &[2]float64{lat, lon}
+	}
+
+	status := widget.NewLabel("")
+	picker := newMapPicker(lat, lon, zoom, func(lat, lon float64) {
+		picked = &[2]float64{lat, lon}
+	})
+	picker.onMove = func() {
+		centerLat, centerLon := picker.center()
+		text := fmt.Sprintf("Centre %s, %s  Zoom %d", helpers.FormatCoordinate(centerLat), helpers.FormatCoordinate(centerLon), picker.zoom)
+		if picked != nil {
+			text += fmt.Sprintf("  Selected %s, %s", helpers.FormatCoordinate(picked[0]), helpers.FormatCoordinate(picked[1]))
+		}
+		status.SetText(text)
+	}
+	if picked != nil {
+		picker.setMarker(picked[0], picked[1])
+	}
+	picker.onMove()
+
+	zoomIn := widget.NewButton("+", func() { picker.setZoom(picker.zoom + 1) })
+	zoomOut := widget.NewButton("-", func() { picker.setZoom(picker.zoom - 1) })
+	content := container.NewBorder(nil, container.NewHBox(zoomOut, zoomIn, status), nil, nil, picker)
+
+	pickerDialog := dialog.NewCustomConfirm("Pick Location", "Use Location", "Cancel", content, func(confirm bool) {
+		if confirm && picked != nil {
+			l.setCoordinates(picked[0], picked[1])
+		}
+	}, window)
+	pickerDialog.Resize(fyne.NewSize(640, 480))
+	pickerDialog.Show()
+}
@@ -0,0 +1,271 @@
+package tabs
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Offline tiles use the standard slippy map layout, e.g. tiles/7/68/45.png.
+const (
+	mapTilesDir = "tiles"
+	mapTileSize = 256
+	mapMinZoom  = 1
+	mapMaxZoom  = 17
+)
+
+var (
+	mapBackground = color.RGBA{214, 230, 240, 255}
+	mapGridMajor  = color.RGBA{150, 170, 185, 255}
+	mapGridMinor  = color.RGBA{190, 205, 215, 255}
+	mapMarker     = color.RGBA{200, 30, 30, 255}
+)
+
+// mapPicker is a pannable Web Mercator map. It draws tiles from mapTilesDir
+// when they are available and falls back to a latitude/longitude grid.
+type mapPicker struct {
+	widget.BaseWidget
+
+	centerX, centerY float64 // world pixel coordinates at the current zoom
+	zoom             int
+	marker           *[2]float64
+	raster           *canvas.Raster
+	tiles            map[string]image.Image
+	onPick           func(lat, lon float64)
+	onMove           func()
+}
+
+func newMapPicker(lat, lon float64, zoom int, onPick func(lat, lon float64)) *mapPicker {
+	picker := &mapPicker{zoom: zoom, tiles: make(map[string]image.Image), onPick: onPick}
+	picker.centerX, picker.centerY = mercatorProject(lat, lon, zoom)
+	picker.raster = canvas.NewRaster(picker.draw)
+	picker.ExtendBaseWidget(picker)
+	return picker
+}
+
+func (m *mapPicker) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(m.raster)
+}
+
+func (m *mapPicker) MinSize() fyne.Size {
+	return fyne.NewSize(560, 360)
+}
+
+func (m *mapPicker) Tapped(event *fyne.PointEvent) {
+	lat, lon := m.positionToLatLon(event.Position)
+	if m.onPick != nil {
+		m.onPick(lat, lon)
+	}
+	m.setMarker(lat, lon)
+}
+
+func (m *mapPicker) Dragged(event *fyne.DragEvent) {
+	scale := m.pixelScale()
+	m.centerX -= float64(event.Dragged.DX) * scale
+	m.centerY -= float64(event.Dragged.DY) * scale
+	m.wrapCenter()
+	m.refresh()
+}
+
+func (m *mapPicker) DragEnd() {}
+
+func (m *mapPicker) Scrolled(event *fyne.ScrollEvent) {
+	if event.Scrolled.DY > 0 {
+		m.setZoom(m.zoom + 1)
+	} else if event.Scrolled.DY < 0 {
+		m.setZoom(m.zoom - 1)
+	}
+}
+
+func (m *mapPicker) setZoom(zoom int) {
+	zoom = max(mapMinZoom, min(mapMaxZoom, zoom))
+	if zoom == m.zoom {
+		return
+	}
+	factor := math.Pow(2, float64(zoom-m.zoom))
+	m.centerX *= factor
+	m.centerY *= factor
+	m.zoom = zoom
+	m.wrapCenter()
+	m.refresh()
+}
+
+// wrapCenter keeps the center within one world width, as the map repeats
+// east and west.
+func (m *mapPicker) wrapCenter() {
+	worldSize := mapTileSize * math.Pow(2, float64(m.zoom))
+	m.centerX = math.Mod(m.centerX, worldSize)
+	if m.centerX < 0 {
+		m.centerX += worldSize
+	}
+}
+
+func (m *mapPicker) setMarker(lat, lon float64) {
+	m.marker = &[2]float64{lat, lon}
+	m.refresh()
+}
+
+func (m *mapPicker) center() (lat, lon float64) {
+	return mercatorUnproject(m.centerX, m.centerY, m.zoom)
+}
+
+func (m *mapPicker) refresh() {
+	m.raster.Refresh()
+	if m.onMove != nil {
+		m.onMove()
+	}
+}
+
+// pixelScale converts canvas units into raster pixels.
+func (m *mapPicker) pixelScale() float64 {
+	if c := fyne.CurrentApp().Driver().CanvasForObject(m); c != nil {
+		return float64(c.Scale())
+	}
+	return 1
+}
+
+func (m *mapPicker) positionToLatLon(pos fyne.Position) (lat, lon float64) {
+	scale := m.pixelScale()
+	size := m.Size()
+	x := m.centerX + (float64(pos.X)-float64(size.Width)/2)*scale
+	y := m.centerY + (float64(pos.Y)-float64(size.Height)/2)*scale
+	return mercatorUnproject(x, y, m.zoom)
+}
+
+func (m *mapPicker) draw(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{mapBackground}, image.Point{}, draw.Src)
+
+	left := m.centerX - float64(w)/2
+	top := m.centerY - float64(h)/2
+	if !m.drawTiles(img, left, top) {
+		m.drawGrid(img, left, top)
+	}
+
+	if m.marker != nil {
+		x, y := mercatorProject(m.marker[0], m.marker[1], m.zoom)
+		// Draw the copy of the marker nearest to the center.
+		worldSize := mapTileSize * math.Pow(2, float64(m.zoom))
+		x -= math.Round((x-m.centerX)/worldSize) * worldSize
+		mx, my := int(x-left), int(y-top)
+		for dy := -6; dy <= 6; dy++ {
+			for dx := -6; dx <= 6; dx++ {
+				if dx*dx+dy*dy <= 36 {
+					img.Set(mx+dx, my+dy, mapMarker)
+				}
+			}
+		}
+	}
+	return img
+}
+
+func (m *mapPicker) drawTiles(img *image.RGBA, left, top float64) bool {
+	bounds := img.Bounds()
+	tileCount := 1 << m.zoom
+	drawn := false
+	for ty := int(math.Floor(top / mapTileSize)); float64(ty*mapTileSize) < top+float64(bounds.Dy()); ty++ {
+		if ty < 0 || ty >= tileCount {
+			continue
+		}
+		for tx := int(math.Floor(left / mapTileSize)); float64(tx*mapTileSize) < left+float64(bounds.Dx()); tx++ {
+			tile := m.tile(m.zoom, (tx%tileCount+tileCount)%tileCount, ty)
+			if tile == nil {
+				continue
+			}
+			origin := image.Pt(int(float64(tx*mapTileSize)-left), int(float64(ty*mapTileSize)-top))
+			draw.Draw(img, image.Rectangle{Min: origin, Max: origin.Add(image.Pt(mapTileSize, mapTileSize))}, tile, tile.Bounds().Min, draw.Src)
+			drawn = true
+		}
+	}
+	return drawn
+}
+
+func (m *mapPicker) tile(zoom, x, y int) image.Image {
+	key := fmt.Sprintf("%d/%d/%d", zoom, x, y)
+	if tile, ok := m.tiles[key]; ok {
+		return tile
+	}
+
+	var tile image.Image
+	for _, ext := range []string{".png", ".jpg"} {
+		file, err := os.Open(filepath.Join(mapTilesDir, key+ext))
+		if err != nil {
+			continue
+		}
+		tile, _, err = image.Decode(file)
+		file.Close()
+		if err == nil {
+			break
+		}
+	}
+	m.tiles[key] = tile
+	return tile
+}
+
+func (m *mapPicker) drawGrid(img *image.RGBA, left, top float64) {
+	bounds := img.Bounds()
+	north, west := mercatorUnproject(left, top, m.zoom)
+	south, east := mercatorUnproject(left+float64(bounds.Dx()), top+float64(bounds.Dy()), m.zoom)
+
+	step := gridStep(east - west)
+	for i := math.Floor(west / step); i*step <= east; i++ {
+		x, _ := mercatorProject(0, i*step, m.zoom)
+		lineColor := mapGridMinor
+		if math.Mod(i*step, step*5) == 0 {
+			lineColor = mapGridMajor
+		}
+		for y := 0; y < bounds.Dy(); y++ {
+			img.Set(int(x-left), y, lineColor)
+		}
+	}
+	for i := math.Floor(south / step); i*step <= north; i++ {
+		_, y := mercatorProject(i*step, 0, m.zoom)
+		lineColor := mapGridMinor
+		if math.Mod(i*step, step*5) == 0 {
+			lineColor = mapGridMajor
+		}
+		for x := 0; x < bounds.Dx(); x++ {
+			img.Set(x, int(y-top), lineColor)
+		}
+	}
+}
+
+func gridStep(spanDegrees float64) float64 {
+	for _, step := range []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10} {
+		if spanDegrees/step <= 20 {
+			return step
+		}
+	}
+	return 30
+}
+
+func mercatorProject(lat, lon float64, zoom int) (x, y float64) {
+	lat = math.Max(-85.0511, math.Min(85.0511, lat))
+	worldSize := mapTileSize * math.Pow(2, float64(zoom))
+	latRad := lat * math.Pi / 180
+	x = (lon + 180) / 360 * worldSize
+	y = (1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * worldSize
+	return x, y
+}
+
+func mercatorUnproject(x, y float64, zoom int) (lat, lon float64) {
+	worldSize := mapTileSize * math.Pow(2, float64(zoom))
+	// Points beside the world, left or right of the center, wrap around.
+	lon = math.Mod(x/worldSize*360, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	lon -= 180
+	lat = math.Atan(math.Sinh(math.Pi*(1-2*y/worldSize))) * 180 / math.Pi
+	return lat, lon
+}
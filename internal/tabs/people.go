@@ -0,0 +1,102 @@
+package tabs
+
+import (
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const maxPeopleSuggestions = 10
+
+// newAuthorEntry returns an entry autocompleting names from the people
+// directory, prefilled with the configured author.
+func newAuthorEntry() *widget.SelectEntry {
+	author := widget.NewSelectEntry(nil)
+	author.SetPlaceHolder("Who wrote it")
+	author.SetText(helpers.LoadConfig().Author)
+	author.OnChanged = func(input string) {
+		author.SetOptions(suggestPeople(input, nil))
+	}
+	author.SetOptions(suggestPeople("", nil))
+	return author
+}
+
+// suggestPeople returns known names containing the input, leaving out the
+// excluded ones.
+func suggestPeople(input string, exclude []string) []string {
+	input = strings.ToLower(strings.TrimSpace(input))
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[strings.ToLower(name)] = true
+	}
+	var suggestions []string
+	for _, name := range helpers.PeopleNames() {
+		if len(suggestions) == maxPeopleSuggestions {
+			break
+		}
+		if strings.Contains(strings.ToLower(name), input) && !excluded[strings.ToLower(name)] {
+			suggestions = append(suggestions, name)
+		}
+	}
+	return suggestions
+}
+
+// participantSection edits who attended an event or trip.
+type participantSection struct {
+	input   *widget.SelectEntry
+	chips   *fyne.Container
+	names   []string
+	content fyne.CanvasObject
+}
+
+func newParticipantSection() *participantSection {
+	p := &participantSection{
+		input: widget.NewSelectEntry(nil),
+		chips: container.NewGridWithColumns(3),
+	}
+	p.input.SetPlaceHolder("Type a name and press Enter")
+	p.input.OnChanged = func(input string) {
+		p.input.SetOptions(suggestPeople(input, p.names))
+	}
+	p.input.OnSubmitted = func(string) { p.addFromInput() }
+	addButton := widget.NewButton("Add Participant", p.addFromInput)
+
+	p.content = container.NewVBox(container.NewBorder(nil, nil, nil, addButton, p.input), p.chips)
+	p.input.SetOptions(suggestPeople("", nil))
+	return p
+}
+
+func (p *participantSection) addFromInput() {
+	p.setNames(append(p.names, p.input.Text))
+	p.input.SetText("")
+}
+
+func (p *participantSection) setNames(names []string) {
+	p.names = helpers.NormalizeNames(names)
+	p.chips.RemoveAll()
+	for _, name := range p.names {
+		name := name
+		p.chips.Add(widget.NewButtonWithIcon(name, theme.CancelIcon(), func() {
+			var remaining []string
+			for _, existing := range p.names {
+				if existing != name {
+					remaining = append(remaining, existing)
+				}
+			}
+			p.setNames(remaining)
+		}))
+	}
+}
+
+func (p *participantSection) values() []string {
+	return append([]string{}, p.names...)
+}
+
+func (p *participantSection) load(document helpers.Document) {
+	p.setNames(document.Participants())
+}
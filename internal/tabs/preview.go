@@ -0,0 +1,38 @@
+package tabs
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// newPreviewButton opens the uploaded file behind a URL field in the
+// browser, pre-signing the link when the bucket is private.
+func newPreviewButton(window fyne.Window, field *widget.Entry) *widget.Button {
+	return widget.NewButton("Preview", func() {
+		if strings.TrimSpace(field.Text) == "" {
+			dialog.ShowError(fmt.Errorf("Nothing uploaded yet"), window)
+			return
+		}
+
+		preview, err := helpers.PreviewURL(strings.TrimSpace(field.Text))
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		parsed, err := url.Parse(preview)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Invalid URL: %v", err), window)
+			return
+		}
+		if err := fyne.CurrentApp().OpenURL(parsed); err != nil {
+			dialog.ShowError(err, window)
+		}
+	})
+}
@@ -1,7 +1,7 @@
 package tabs
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"image/color"
 	"os"
@@ -19,7 +19,7 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
-func NewReportTab(window fyne.Window) *container.TabItem {
+func NewReportTab(window fyne.Window) (*container.TabItem, DocumentLoader) {
 	// Input fields
 	entryType := widget.NewEntry()
 	entryType.SetText("Report")
@@ -31,7 +31,10 @@ func NewReportTab(window fyne.Window) *container.TabItem {
 	relatedTripURL := widget.NewEntry()
 	relatedEventURL := widget.NewEntry()
 	uniqueReportID := widget.NewEntry()
-	googleMapURL := widget.NewEntry()
+	prefix := newUploadPrefix(uniqueReportID, "Report")
+	author := newAuthorEntry()
+	googleMapField := newGoogleMapField()
+	googleMapURL := googleMapField.entry
 
 	// Rich text description
 	description := widget.NewRichText()
@@ -102,6 +105,14 @@ func NewReportTab(window fyne.Window) *container.TabItem {
 
 	// S3 File Uploads
 	mainImagePath := widget.NewEntry()
+	mainImageAltText := widget.NewEntry()
+	mainImageAltText.SetPlaceHolder(fmt.Sprintf("What the photo shows, under %d characters", helpers.MaxAltTextLength))
+	mainImageCaption := widget.NewEntry()
+	location := newLocationSection(window)
+	conditions := newConditionsSection(window, reportDate, location)
+	uploads := newUploadManager(window)
+	prefillFromMetadata := widget.NewCheck("Prefill details from photo metadata", nil)
+	prefillFromMetadata.SetChecked(true)
 	mainImageUploadButton := widget.NewButton("Upload Main Image", func() {
 		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
 			if err != nil || reader == nil {
@@ -109,99 +120,140 @@ func NewReportTab(window fyne.Window) *container.TabItem {
 			}
 			defer reader.Close()
 
-			uploadPath := fmt.Sprintf("%s/main.webp", uniqueReportID.Text)
-			url, err := helpers.UploadToS3(uploadPath, reader.URI().Path())
-			if err != nil {
-				dialog.ShowError(err, window)
-				return
-			}
-
-			mainImagePath.SetText(url)
-			dialog.ShowInformation("Success", "Main image uploaded successfully", window)
-		}, window)
-	})
-
-	subImageContainer := container.NewVBox()
-	addSubImageButton := widget.NewButton("Add Sub Image", func() {
-		subImagePath := widget.NewEntry()
-		subImageName := widget.NewEntry()
-		subImageDescription := widget.NewMultiLineEntry()
-		subImageDescription.Wrapping = fyne.TextWrapWord
-		subImageDescription.Resize(fyne.NewSize(0, 100))
-		subImageUploadButton := widget.NewButton("Upload Sub Image", func() {
-			dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
-				if err != nil || reader == nil {
-					return
-				}
-				defer reader.Close()
-
-				imageIndex := len(subImageContainer.Objects) + 1
-				uploadPath := fmt.Sprintf("%s/subImages/image%d.webp", uniqueReportID.Text, imageIndex)
-				url, err := helpers.UploadToS3(uploadPath, reader.URI().Path())
+			uploadPath := fmt.Sprintf("%s/main.webp", prefix.next())
+			uploadOptions := prefix.options()
+			uploadOptions.AltText = mainImageAltText.Text
+			filePath := reader.URI().Path()
+			uploads.start("Main image", func(ctx context.Context, progress helpers.ProgressFunc) error {
+				url, metadata, err := helpers.UploadImage(ctx, uploadPath, filePath, uploadOptions, progress)
 				if err != nil {
-					dialog.ShowError(err, window)
-					return
+					return err
 				}
 
-				subImagePath.SetText(url)
-				dialog.ShowInformation("Success", "Sub image uploaded successfully", window)
-			}, window)
-		})
+				onMain(func() {
+					mainImagePath.SetText(url)
+					if prefillFromMetadata.Checked {
+						location.fillFromMetadata(metadata)
+					}
+				})
+				return nil
+			})
+		}, window)
+	})
 
-		subImageItem := container.NewVBox(
-			widget.NewLabel("Sub Image Description:"), subImageDescription,
-			widget.NewLabel("Sub Image Name:"), subImageName,
-			widget.NewLabel("Sub Image URL:"), subImagePath,
-			subImageUploadButton,
-		)
+	subImages := newSubImageSection(window, prefix, prefillFromMetadata, location, uploads)
 
-		subImageContainer.Add(subImageItem)
-	})
+	// Video, PDF and GPX attachments
+	attachments := newAttachmentSection(window, prefix, uploads)
 
 	// Publish button logic
 	publishButton := widget.NewButton("Publish", func() {
+		if uploads.busy() {
+			dialog.ShowError(fmt.Errorf("Please wait for the uploads to finish"), window)
+			return
+		}
+		if googleMapField.busy() {
+			dialog.ShowError(fmt.Errorf("Please wait for the Google Maps link to resolve"), window)
+			return
+		}
+
 		if reportDate.Text == "" || reportType.Selected == "" || reportName.Text == "" || uniqueReportID.Text == "" {
 			dialog.ShowError(fmt.Errorf("Please fill all required fields"), window)
 			return
 		}
 
-		reportData := map[string]interface{}{
-			"EntryType":       entryType.Text,
-			"ReportDate":      reportDate.Text,
-			"ReportType":      reportType.Selected,
-			"ReportName":      reportName.Text,
-			"RelatedTripURL":  relatedTripURL.Text,
-			"RelatedEventURL": relatedEventURL.Text,
-			"UniqueReportID":  uniqueReportID.Text,
-			"GoogleMapURL":    googleMapURL.Text,
-			"MainImagePath":   mainImagePath.Text,
-			"Description":     descriptionEntry.Text,
-			"SubImages":       helpers.GetSubImageData(subImageContainer),
+		googleMap := googleMapField.location()
+
+		documentLocation, err := location.location()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
 		}
 
-		jsonData, err := json.MarshalIndent(reportData, "", "  ")
+		documentConditions, err := conditions.values()
 		if err != nil {
 			dialog.ShowError(err, window)
 			return
 		}
 
-		outputFolder := "output/reports"
-		if _, err := os.Stat(outputFolder); os.IsNotExist(err) {
-			err = os.MkdirAll(outputFolder, os.ModePerm)
+		reportData := map[string]interface{}{
+			"EntryType":        entryType.Text,
+			"ReportDate":       reportDate.Text,
+			"ReportType":       reportType.Selected,
+			"ReportName":       reportName.Text,
+			"Author":           strings.TrimSpace(author.Text),
+			"RelatedTripURL":   relatedTripURL.Text,
+			"RelatedEventURL":  relatedEventURL.Text,
+			"UniqueReportID":   uniqueReportID.Text,
+			"GoogleMapURL":     googleMapURL.Text,
+			"GoogleMap":        googleMap,
+			"MainImagePath":    mainImagePath.Text,
+			"MainImageAltText": mainImageAltText.Text,
+			"MainImageCaption": mainImageCaption.Text,
+			"Location":         documentLocation,
+			"Conditions":       documentConditions,
+			"Description":      descriptionEntry.Text,
+			"SubImages":        helpers.GetSubImageData(subImages.container),
+			"Attachments":      attachments.attachments(),
+		}
+
+		// write runs once the files are in place, with their final URLs.
+		write := func() {
+			reportData["MainImagePath"] = mainImagePath.Text
+			reportData["SubImages"] = helpers.GetSubImageData(subImages.container)
+			reportData["Attachments"] = attachments.attachments()
+
+			jsonData, err := helpers.MarshalDocument(reportData)
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+
+			outputFolder := "output/reports"
+			if _, err := os.Stat(outputFolder); os.IsNotExist(err) {
+				err = os.MkdirAll(outputFolder, os.ModePerm)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Failed to create output folder: %v", err), window)
+					return
+				}
+			}
+
+			fileName := filepath.Join(outputFolder, fmt.Sprintf("%s_%s.json", uniqueReportID.Text, reportName.Text))
+			err = os.WriteFile(fileName, jsonData, 0644)
 			if err != nil {
-				dialog.ShowError(fmt.Errorf("Failed to create output folder: %v", err), window)
+				dialog.ShowError(err, window)
 				return
 			}
+			helpers.DocumentPublished(fileName)
+
+			dialog.ShowInformation("Success", fmt.Sprintf("Report saved as %s", fileName), window)
 		}
 
-		fileName := filepath.Join(outputFolder, fmt.Sprintf("%s_%s.json", uniqueReportID.Text, reportName.Text))
-		err = os.WriteFile(fileName, jsonData, 0644)
-		if err != nil {
-			dialog.ShowError(err, window)
-			return
+		// Move files uploaded before the ID was set under the final ID,
+		// only once publishing is confirmed
+		save := func() {
+			stagedFields := append([]*widget.Entry{mainImagePath}, subImages.urlFields()...)
+			stagedFields = append(stagedFields, attachments.urlFields()...)
+			relocate := prefix.relocate(stagedFields...)
+			uploads.start(fmt.Sprintf("Publish %s", uniqueReportID.Text), func(ctx context.Context, progress helpers.ProgressFunc) error {
+				if err := relocate(ctx, progress); err != nil {
+					return err
+				}
+				onMain(write)
+				return nil
+			})
 		}
 
-		dialog.ShowInformation("Success", fmt.Sprintf("Report saved as %s", fileName), window)
+		if warnings := helpers.AccessibilityWarnings(reportData); len(warnings) > 0 {
+			message := fmt.Sprintf("%s\n\nPublish anyway?", strings.Join(warnings, "\n"))
+			dialog.ShowConfirm("Accessibility warnings", message, func(publish bool) {
+				if publish {
+					save()
+				}
+			}, window)
+			return
+		}
+		save()
 	})
 
 	content := container.NewVBox(
@@ -212,16 +264,45 @@ func NewReportTab(window fyne.Window) *container.TabItem {
 		widget.NewLabel("Related Trip URL:"), relatedTripURL,
 		widget.NewLabel("Related Event URL:"), relatedEventURL,
 		widget.NewLabel("Unique Report ID*:"), uniqueReportID,
-		widget.NewLabel("Unique Google Map URL:"), googleMapURL,
-		widget.NewLabel("Main Image:"), container.NewHBox(mainImagePath, mainImageUploadButton),
+		widget.NewLabel("Author:"), author,
+		widget.NewLabel("Unique Google Map URL:"), googleMapField.content,
+		widget.NewLabel("Location:"), location.content,
+		widget.NewLabel("Conditions:"), conditions.content,
+		prefillFromMetadata,
+		widget.NewLabel("Main Image:"), container.NewHBox(mainImagePath, mainImageUploadButton, newPreviewButton(window, mainImagePath)),
+		widget.NewLabel("Main Image Alt Text:"), mainImageAltText,
+		widget.NewLabel("Main Image Caption:"), mainImageCaption,
 		widget.NewLabel("Description:"), descriptionContainer,
-		widget.NewLabel("Sub Images:"), subImageContainer, addSubImageButton,
+		widget.NewLabel("Sub Images:"), subImages.content,
+		widget.NewLabel("Attachments:"), attachments.content,
+		widget.NewLabel("Uploads:"), uploads.content,
 		layout.NewSpacer(),
 		publishButton,
 	)
 
+	// Fill the form with a published report for editing
+	load := func(document helpers.Document) {
+		loadEntries(document, map[string]*widget.Entry{
+			"ReportDate":       reportDate,
+			"ReportName":       reportName,
+			"RelatedTripURL":   relatedTripURL,
+			"RelatedEventURL":  relatedEventURL,
+			"UniqueReportID":   uniqueReportID,
+			"GoogleMapURL":     googleMapURL,
+			"MainImagePath":    mainImagePath,
+			"MainImageAltText": mainImageAltText,
+			"MainImageCaption": mainImageCaption,
+			"Description":      descriptionEntry,
+		})
+		reportType.SetSelected(document.Text("ReportType"))
+		author.SetText(document.Text("Author"))
+		conditions.load(document)
+
+		loadSections(document, prefix, location, subImages, attachments)
+	}
+
 	scrollableContent := container.NewVScroll(content)
-	return container.NewTabItem("Report", scrollableContent)
+	return container.NewTabItem("Report", scrollableContent), load
 }
 
 func richTextToHTML(segments []widget.RichTextSegment) string {
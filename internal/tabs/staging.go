@@ -0,0 +1,118 @@
+package tabs
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2/widget"
+)
+
+// uploadPrefix picks the key prefix for uploads of one document. Until the
+// unique ID is filled in, files are staged under a draft prefix; every prefix
+// used is remembered so publish can move the files under the final ID.
+type uploadPrefix struct {
+	documentID   *widget.Entry
+	documentType string
+	draft        string
+
+	mu   sync.Mutex
+	used map[string]bool
+}
+
+func newUploadPrefix(documentID *widget.Entry, documentType string) *uploadPrefix {
+	return &uploadPrefix{
+		documentID:   documentID,
+		documentType: documentType,
+		draft:        helpers.NewDraftPrefix(),
+		used:         make(map[string]bool),
+	}
+}
+
+// options returns the metadata to store with an upload of the document.
+func (p *uploadPrefix) options() helpers.UploadOptions {
+	return helpers.UploadOptions{
+		DocumentID:   strings.TrimSpace(p.documentID.Text),
+		DocumentType: p.documentType,
+	}
+}
+
+// next returns the prefix for a new upload and records it as used.
+func (p *uploadPrefix) next() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prefix := strings.TrimSpace(p.documentID.Text)
+	if prefix == "" {
+		prefix = p.draft
+	}
+	p.used[prefix] = true
+	return prefix
+}
+
+// reset starts over for a document loaded into the form. Only its ID
+// counts as used and later drafts get a fresh prefix, so its files move
+// along when the ID is changed before publishing again, while files of
+// documents loaded earlier are never moved under another ID.
+func (p *uploadPrefix) reset(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.draft = helpers.NewDraftPrefix()
+	p.used = make(map[string]bool)
+	if id = strings.TrimSpace(id); id != "" {
+		p.used[id] = true
+	}
+}
+
+// relocate returns a task for the upload manager that moves the objects
+// behind the given URL fields from draft or previous ID prefixes to the
+// current document ID and updates the fields as it goes. The fields are read
+// when relocate is called, so it must run on the UI thread. Objects under
+// foreign prefixes stay put.
+func (p *uploadPrefix) relocate(fields ...*widget.Entry) uploadFunc {
+	final := strings.TrimSpace(p.documentID.Text)
+	urls := make([]string, len(fields))
+	for i, field := range fields {
+		urls[i] = field.Text
+	}
+
+	// A retry skips the objects already moved, as urls follows the fields.
+	return func(ctx context.Context, progress helpers.ProgressFunc) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		moves := make(map[string]string)
+		var sources []string
+		for _, url := range urls {
+			key, ok := helpers.KeyFromURL(url)
+			if _, seen := moves[key]; !ok || seen {
+				continue
+			}
+			for prefix := range p.used {
+				if prefix != final && strings.HasPrefix(key, prefix+"/") {
+					moves[key] = final + strings.TrimPrefix(key, prefix)
+					sources = append(sources, key)
+					break
+				}
+			}
+		}
+
+		for i, key := range sources {
+			url, err := helpers.MoveObject(ctx, key, moves[key])
+			if err != nil {
+				return err
+			}
+			for j, field := range fields {
+				if fieldKey, ok := helpers.KeyFromURL(urls[j]); ok && fieldKey == key {
+					urls[j] = url
+					onMain(func() { field.SetText(url) })
+				}
+			}
+			progress(float64(i+1) / float64(len(sources)))
+		}
+
+		p.used = map[string]bool{final: true}
+		return nil
+	}
+}
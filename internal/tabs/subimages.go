@@ -0,0 +1,228 @@
+package tabs
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var importableImageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+}
+
+var subImageIndexPattern = regexp.MustCompile(`/subImages/image(\d+)\.[a-z]+$`)
+
+type subImageRow struct {
+	index       int
+	name        *widget.Entry
+	description *widget.Entry
+	altText     *widget.Entry
+	url         *widget.Entry
+}
+
+// subImageSection holds the dynamic sub-image rows shared by all tabs.
+type subImageSection struct {
+	window    fyne.Window
+	prefix    *uploadPrefix
+	prefill   *widget.Check
+	location  *locationSection
+	uploads   *uploadManager
+	rows      []*subImageRow
+	nextIndex int
+	container *fyne.Container
+	content   fyne.CanvasObject
+}
+
+func newSubImageSection(window fyne.Window, prefix *uploadPrefix, prefill *widget.Check, location *locationSection, uploads *uploadManager) *subImageSection {
+	s := &subImageSection{
+		window:    window,
+		prefix:    prefix,
+		prefill:   prefill,
+		location:  location,
+		uploads:   uploads,
+		nextIndex: 1,
+		container: container.NewVBox(),
+	}
+
+	addSubImageButton := widget.NewButton("Add Sub Image", func() {
+		s.addRow()
+	})
+	importFolderButton := widget.NewButton("Import Folder…", func() {
+		dialog.ShowFolderOpen(func(folder fyne.ListableURI, err error) {
+			if err != nil || folder == nil {
+				return
+			}
+			s.importFolder(folder)
+		}, window)
+	})
+
+	s.content = container.NewVBox(s.container, container.NewHBox(addSubImageButton, importFolderButton))
+	return s
+}
+
+func (s *subImageSection) addRow() *subImageRow {
+	return s.addRowWithIndex(s.nextIndex)
+}
+
+func (s *subImageSection) addRowWithIndex(index int) *subImageRow {
+	s.nextIndex = max(s.nextIndex, index+1)
+	row := &subImageRow{
+		index:       index,
+		name:        widget.NewEntry(),
+		description: widget.NewMultiLineEntry(),
+		altText:     widget.NewEntry(),
+		url:         widget.NewEntry(),
+	}
+	row.altText.SetPlaceHolder(fmt.Sprintf("What the photo shows, under %d characters", helpers.MaxAltTextLength))
+	row.description.Wrapping = fyne.TextWrapWord
+
+	uploadButton := widget.NewButton("Upload Sub Image", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+
+			s.upload(fmt.Sprintf("Sub image %d", row.index), row, reader.URI().Path(), func(metadata helpers.ImageMetadata) {
+				fillSubImageFromMetadata(row.name, row.description, metadata)
+			})
+		}, s.window)
+	})
+
+	s.container.Add(container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Sub Image %d Description:", row.index)),
+		row.description,
+		widget.NewLabel("Sub Image Name:"), row.name,
+		widget.NewLabel("Sub Image Alt Text:"), row.altText,
+		widget.NewLabel("Sub Image URL:"), row.url,
+		container.NewHBox(uploadButton, newPreviewButton(s.window, row.url)),
+	))
+	s.rows = append(s.rows, row)
+	return row
+}
+
+// load replaces the rows with the sub-images of a published document.
+// Rows keep the number in their key, so new uploads never overwrite an
+// existing image.
+func (s *subImageSection) load(subImages []map[string]interface{}) {
+	s.container.RemoveAll()
+	s.rows = nil
+	s.nextIndex = 1
+	for _, subImage := range subImages {
+		index := s.nextIndex
+		if match := subImageIndexPattern.FindStringSubmatch(stringField(subImage, "URL")); match != nil {
+			index, _ = strconv.Atoi(match[1])
+		}
+		row := s.addRowWithIndex(index)
+		row.name.SetText(stringField(subImage, "Name"))
+		row.description.SetText(stringField(subImage, "Description"))
+		row.altText.SetText(stringField(subImage, "AltText"))
+		row.url.SetText(stringField(subImage, "URL"))
+	}
+}
+
+// urlFields returns the URL entries of all rows.
+func (s *subImageSection) urlFields() []*widget.Entry {
+	fields := make([]*widget.Entry, len(s.rows))
+	for i, row := range s.rows {
+		fields[i] = row.url
+	}
+	return fields
+}
+
+// upload queues the upload of an image for the row. Once it is done the
+// row gets its URL and, with prefilling enabled, the location and fill are
+// given the metadata of the image.
+func (s *subImageSection) upload(name string, row *subImageRow, filePath string, fill func(helpers.ImageMetadata)) {
+	uploadPath := fmt.Sprintf("%s/subImages/image%d.webp", s.prefix.next(), row.index)
+	uploadOptions := s.prefix.options()
+	uploadOptions.AltText = row.altText.Text
+	s.uploads.start(name, func(ctx context.Context, progress helpers.ProgressFunc) error {
+		url, metadata, err := helpers.UploadImage(ctx, uploadPath, filePath, uploadOptions, progress)
+		if err != nil {
+			return err
+		}
+		onMain(func() {
+			row.url.SetText(url)
+			if s.prefill.Checked {
+				s.location.fillFromMetadata(metadata)
+				fill(metadata)
+			}
+		})
+		return nil
+	})
+}
+
+// importFolder adds one row per image in the folder and queues their
+// uploads, which run in parallel in the upload manager.
+func (s *subImageSection) importFolder(folder fyne.ListableURI) {
+	items, err := folder.List()
+	if err != nil {
+		dialog.ShowError(err, s.window)
+		return
+	}
+	var files []fyne.URI
+	for _, item := range items {
+		if importableImageExtensions[strings.ToLower(item.Extension())] {
+			files = append(files, item)
+		}
+	}
+	if len(files) == 0 {
+		dialog.ShowInformation("Import Folder", "The selected folder contains no images", s.window)
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	for _, file := range files {
+		row := s.addRow()
+		row.name.SetText(nameFromFileName(file.Name(), file.Extension()))
+		s.upload(file.Name(), row, file.Path(), func(metadata helpers.ImageMetadata) {
+			// The EXIF title wins over the name derived from the file.
+			if metadata.Title != "" {
+				row.name.SetText(metadata.Title)
+			}
+			fillSubImageFromMetadata(row.name, row.description, metadata)
+		})
+	}
+}
+
+// nameFromFileName turns "pan_di-zucchero.jpg" into "Pan Di Zucchero".
+func nameFromFileName(fileName, extension string) string {
+	words := strings.FieldsFunc(strings.TrimSuffix(fileName, extension), func(r rune) bool {
+		return r == '_' || r == '-' || r == ' ' || r == '.'
+	})
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = strings.ToUpper(string(runes[0])) + string(runes[1:])
+	}
+	return strings.Join(words, " ")
+}
+
+// fillSubImageFromMetadata fills empty sub-image fields from the photo's
+// EXIF title, capture time and camera.
+func fillSubImageFromMetadata(name, description *widget.Entry, metadata helpers.ImageMetadata) {
+	if name.Text == "" && metadata.Title != "" {
+		name.SetText(metadata.Title)
+	}
+	if description.Text != "" || metadata.CaptureTime.IsZero() {
+		return
+	}
+	text := fmt.Sprintf("Taken on %s", metadata.CaptureTime.Format("2006-01-02 15:04"))
+	if camera := metadata.Camera(); camera != "" {
+		text += fmt.Sprintf(" with %s", camera)
+	}
+	description.SetText(text)
+}
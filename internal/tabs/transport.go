@@ -0,0 +1,180 @@
+package tabs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+type transportRow struct {
+	mode       *widget.SelectEntry
+	from       *widget.Entry
+	to         *widget.Entry
+	departure  *widget.Entry
+	arrival    *widget.Entry
+	operator   *widget.Entry
+	cost       *widget.Entry
+	currency   *widget.SelectEntry
+	bookingURL *widget.Entry
+	content    fyne.CanvasObject
+}
+
+// transportSection edits the legs of the journey to an event or trip and
+// shows the total travel time.
+type transportSection struct {
+	currencies []string
+	rows       []*transportRow
+	container  *fyne.Container
+	total      *widget.Label
+	content    fyne.CanvasObject
+}
+
+func newTransportSection() *transportSection {
+	s := &transportSection{
+		container: container.NewVBox(),
+		total:     widget.NewLabel(""),
+	}
+	if rates, err := helpers.LoadExchangeRates(); err == nil {
+		s.currencies = rates.Currencies()
+	}
+
+	addButton := widget.NewButton("Add Leg", func() {
+		s.addRow(helpers.TransportLeg{})
+	})
+	s.content = container.NewVBox(s.container, addButton, s.total)
+	s.update()
+	return s
+}
+
+func (s *transportSection) addRow(leg helpers.TransportLeg) {
+	row := &transportRow{
+		mode:       widget.NewSelectEntry(helpers.TransportModes),
+		from:       widget.NewEntry(),
+		to:         widget.NewEntry(),
+		departure:  widget.NewEntry(),
+		arrival:    widget.NewEntry(),
+		operator:   widget.NewEntry(),
+		cost:       widget.NewEntry(),
+		currency:   widget.NewSelectEntry(s.currencies),
+		bookingURL: widget.NewEntry(),
+	}
+	row.mode.SetPlaceHolder("Mode")
+	row.from.SetPlaceHolder("From")
+	row.to.SetPlaceHolder("To")
+	row.departure.SetPlaceHolder("Departure, e.g. 2024-07-18 08:15")
+	row.arrival.SetPlaceHolder("Arrival, e.g. 11:40")
+	row.operator.SetPlaceHolder("Operator")
+	row.cost.SetPlaceHolder("Cost")
+	row.currency.SetPlaceHolder("Currency")
+	row.bookingURL.SetPlaceHolder("Booking URL")
+
+	row.mode.SetText(leg.Mode)
+	row.from.SetText(leg.From)
+	row.to.SetText(leg.To)
+	row.departure.SetText(leg.Departure)
+	row.arrival.SetText(leg.Arrival)
+	row.operator.SetText(leg.Operator)
+	if leg.Cost > 0 {
+		row.cost.SetText(strconv.FormatFloat(leg.Cost, 'f', 2, 64))
+	}
+	row.currency.SetText(leg.Currency)
+	if leg.Currency == "" && len(s.currencies) > 0 {
+		row.currency.SetText(s.currencies[0])
+	}
+	row.bookingURL.SetText(leg.BookingURL)
+	row.from.OnChanged = func(string) { s.update() }
+	row.to.OnChanged = func(string) { s.update() }
+	row.departure.OnChanged = func(string) { s.update() }
+	row.arrival.OnChanged = func(string) { s.update() }
+
+	removeButton := widget.NewButton("Remove", func() {
+		for i, r := range s.rows {
+			if r == row {
+				s.rows = append(s.rows[:i], s.rows[i+1:]...)
+				break
+			}
+		}
+		s.container.Remove(row.content)
+		s.update()
+	})
+	row.content = container.NewVBox(
+		container.NewBorder(nil, nil, nil, removeButton, container.NewGridWithColumns(3, row.mode, row.from, row.to)),
+		container.NewGridWithColumns(3, row.departure, row.arrival, row.operator),
+		container.NewGridWithColumns(3, row.cost, row.currency, row.bookingURL),
+		widget.NewSeparator(),
+	)
+	s.container.Add(row.content)
+	s.rows = append(s.rows, row)
+	s.update()
+}
+
+// update shows the total travel time below the legs.
+func (s *transportSection) update() {
+	legs, err := s.values()
+	switch {
+	case err != nil:
+		s.total.SetText(err.Error())
+	case len(legs) == 0:
+		s.total.SetText("No transport legs, the Transportation text is published as is")
+	case helpers.TravelMinutes(legs) == 0:
+		s.total.SetText("Add departure and arrival times to calculate the travel time")
+	default:
+		s.total.SetText(fmt.Sprintf("Travel time %s", helpers.FormatDuration(helpers.TravelMinutes(legs))))
+	}
+}
+
+// values validates the rows and returns the legs. Rows without a start or
+// destination are skipped.
+func (s *transportSection) values() ([]helpers.TransportLeg, error) {
+	var legs []helpers.TransportLeg
+	for i, row := range s.rows {
+		from, to := strings.TrimSpace(row.from.Text), strings.TrimSpace(row.to.Text)
+		if from == "" && to == "" {
+			continue
+		}
+		leg := helpers.TransportLeg{
+			Mode:       strings.TrimSpace(row.mode.Text),
+			From:       from,
+			To:         to,
+			Departure:  strings.TrimSpace(row.departure.Text),
+			Arrival:    strings.TrimSpace(row.arrival.Text),
+			Operator:   strings.TrimSpace(row.operator.Text),
+			Currency:   strings.ToUpper(strings.TrimSpace(row.currency.Text)),
+			BookingURL: strings.TrimSpace(row.bookingURL.Text),
+		}
+		if text := strings.TrimSpace(row.cost.Text); text != "" {
+			cost, err := helpers.ParseAmount(text)
+			if err != nil {
+				return nil, fmt.Errorf("transport leg %d: %v", i+1, err)
+			}
+			leg.Cost = cost
+		}
+		leg, err := helpers.NewTransportLeg(leg)
+		if err != nil {
+			return nil, fmt.Errorf("transport leg %d: %v", i+1, err)
+		}
+		legs = append(legs, leg)
+	}
+	return legs, nil
+}
+
+// load replaces the rows with the legs of a published document and reports
+// whether it had any.
+func (s *transportSection) load(document helpers.Document) bool {
+	var legs []helpers.TransportLeg
+	decodeField(document, "TransportLegs", &legs)
+
+	s.container.RemoveAll()
+	s.rows = nil
+	for _, leg := range legs {
+		s.addRow(leg)
+	}
+	s.update()
+	return len(legs) > 0
+}
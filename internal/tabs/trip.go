@@ -1,10 +1,11 @@
 package tabs
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
@@ -14,11 +15,12 @@ import (
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
-func NewTripTab(window fyne.Window) *container.TabItem {
+func NewTripTab(window fyne.Window) (*container.TabItem, DocumentLoader) {
 	currentDate := time.Now().Format("2006-01-02")
 	creationDate := widget.NewEntry()
 	creationDate.SetText(currentDate)
@@ -31,9 +33,26 @@ func NewTripTab(window fyne.Window) *container.TabItem {
 	tripStartDate := widget.NewEntry()
 	tripEndDate := widget.NewEntry()
 	uniqueTripID := widget.NewEntry()
-	uniqueGoogleMapURL := widget.NewEntry()
+	prefix := newUploadPrefix(uniqueTripID, "Trip")
+	author := newAuthorEntry()
+	participants := newParticipantSection()
+	googleMapField := newGoogleMapField()
+	uniqueGoogleMapURL := googleMapField.entry
 	uniqueReportURL := widget.NewEntry()
 	mainImagePath := widget.NewEntry()
+	mainImageAltText := widget.NewEntry()
+	mainImageAltText.SetPlaceHolder(fmt.Sprintf("What the photo shows, under %d characters", helpers.MaxAltTextLength))
+	mainImageCaption := widget.NewEntry()
+	location := newLocationSection(window)
+	classification := newClassificationSection()
+	costBreakdown := newCostSection()
+	gear := newGearSection(window)
+	transport := newTransportSection()
+	itinerary := newItinerarySection(window, tripStartDate, tripEndDate)
+	accommodations := newAccommodationSection(itinerary.setAccommodations)
+	uploads := newUploadManager(window)
+	prefillFromMetadata := widget.NewCheck("Prefill details from photo metadata", nil)
+	prefillFromMetadata.SetChecked(true)
 
 	createRichTextArea := func(label string) (*widget.RichText, *widget.Entry, *widget.Toolbar) {
 		richText := widget.NewRichText()
@@ -111,23 +130,65 @@ func NewTripTab(window fyne.Window) *container.TabItem {
 			}
 			defer reader.Close()
 
-			uploadPath := fmt.Sprintf("%s/main.webp", uniqueTripID.Text)
-			url, err := helpers.UploadToS3(uploadPath, reader.URI().Path())
-			if err != nil {
-				dialog.ShowError(err, window)
+			uploadPath := fmt.Sprintf("%s/main.webp", prefix.next())
+			uploadOptions := prefix.options()
+			uploadOptions.AltText = mainImageAltText.Text
+			filePath := reader.URI().Path()
+			uploads.start("Main image", func(ctx context.Context, progress helpers.ProgressFunc) error {
+				url, metadata, err := helpers.UploadImage(ctx, uploadPath, filePath, uploadOptions, progress)
+				if err != nil {
+					return err
+				}
+
+				onMain(func() {
+					mainImagePath.SetText(url)
+					if prefillFromMetadata.Checked {
+						location.fillFromMetadata(metadata)
+					}
+				})
+				return nil
+			})
+		}, window)
+	})
+
+	// GPX track and elevation profile
+	gpxTrackPath := widget.NewEntry()
+	elevationProfilePath := widget.NewEntry()
+	var trackStats *helpers.TrackStats
+	gpxUploadButton := widget.NewButton("Attach GPX Track", func() {
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
 				return
 			}
+			defer reader.Close()
+
+			documentID := prefix.next()
+			uploadOptions := prefix.options()
+			filePath := reader.URI().Path()
+			uploads.start("GPX track", func(ctx context.Context, progress helpers.ProgressFunc) error {
+				track, err := helpers.UploadTrack(ctx, documentID, filePath, uploadOptions, progress)
+				if err != nil {
+					return err
+				}
 
-			mainImagePath.SetText(url)
-			dialog.ShowInformation("Success", "Main image uploaded successfully", window)
+				onMain(func() {
+					gpxTrackPath.SetText(track.GPXURL)
+					elevationProfilePath.SetText(track.ElevationProfileURL)
+					trackStats = &track.Stats
+					classification.suggestDistance(track.Stats.DistanceKm)
+				})
+				return nil
+			})
 		}, window)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".gpx"}))
+		fileDialog.Show()
 	})
 
 	relatedEventsContainer := container.NewVBox()
-	addEventButton := widget.NewButton("Add Related Event", func() {
-		eventName := widget.NewEntry()
-		eventDescription := widget.NewMultiLineEntry()
-		eventURL := widget.NewEntry()
+	addRelatedEvent := func() (eventName, eventDescription, eventURL *widget.Entry) {
+		eventName = widget.NewEntry()
+		eventDescription = widget.NewMultiLineEntry()
+		eventURL = widget.NewEntry()
 
 		eventItem := container.NewVBox(
 			widget.NewLabel(fmt.Sprintf("Related Event %d", len(relatedEventsContainer.Objects)+1)),
@@ -137,45 +198,16 @@ func NewTripTab(window fyne.Window) *container.TabItem {
 		)
 
 		relatedEventsContainer.Add(eventItem)
+		return eventName, eventDescription, eventURL
+	}
+	addEventButton := widget.NewButton("Add Related Event", func() {
+		addRelatedEvent()
 	})
 
-	subImageContainer := container.NewVBox()
-	addSubImageButton := widget.NewButton("Add Sub Image", func() {
-		subImagePath := widget.NewEntry()
-		subImageName := widget.NewEntry()
-		subImageDescription := widget.NewMultiLineEntry()
-		subImageDescription.Wrapping = fyne.TextWrapWord
-
-		subImageUploadButton := widget.NewButton("Upload Sub Image", func() {
-			dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
-				if err != nil || reader == nil {
-					return
-				}
-				defer reader.Close()
-
-				imageIndex := len(subImageContainer.Objects) + 1
-				uploadPath := fmt.Sprintf("%s/subImages/image%d.webp", uniqueTripID.Text, imageIndex)
-				url, err := helpers.UploadToS3(uploadPath, reader.URI().Path())
-				if err != nil {
-					dialog.ShowError(err, window)
-					return
-				}
+	subImages := newSubImageSection(window, prefix, prefillFromMetadata, location, uploads)
 
-				subImagePath.SetText(url)
-				dialog.ShowInformation("Success", "Sub image uploaded successfully", window)
-			}, window)
-		})
-
-		subImageItem := container.NewVBox(
-			widget.NewLabel(fmt.Sprintf("Sub Image %d Description:", len(subImageContainer.Objects)+1)),
-			subImageDescription,
-			widget.NewLabel("Sub Image Name:"), subImageName,
-			widget.NewLabel("Sub Image URL:"), subImagePath,
-			subImageUploadButton,
-		)
-
-		subImageContainer.Add(subImageItem)
-	})
+	// Video, PDF and GPX attachments
+	attachments := newAttachmentSection(window, prefix, uploads)
 
 	getRelatedEventsData := func() []map[string]string {
 		var events []map[string]string
@@ -209,53 +241,197 @@ func NewTripTab(window fyne.Window) *container.TabItem {
 	}
 
 	publishButton := widget.NewButton("Publish", func() {
+		if uploads.busy() {
+			dialog.ShowError(fmt.Errorf("Please wait for the uploads to finish"), window)
+			return
+		}
+		if googleMapField.busy() {
+			dialog.ShowError(fmt.Errorf("Please wait for the Google Maps link to resolve"), window)
+			return
+		}
+
 		// Validate required fields
-		if tripName.Text == "" || tripStartDate.Text == "" || tripEndDate.Text == "" || uniqueTripID.Text == "" || descriptionEntry.Text == "" || transportationEntry.Text == "" || accommodationEntry.Text == "" {
+		if tripName.Text == "" || tripStartDate.Text == "" || tripEndDate.Text == "" || uniqueTripID.Text == "" || descriptionEntry.Text == "" {
 			dialog.ShowError(fmt.Errorf("Please fill all required fields"), window)
 			return
 		}
-		tripData := map[string]interface{}{
-			"CreationDate":       creationDate.Text,
-			"EntryType":          entryType.Text,
-			"TripName":           tripName.Text,
-			"TripStartDate":      tripStartDate.Text,
-			"TripEndDate":        tripEndDate.Text,
-			"UniqueTripID":       uniqueTripID.Text,
-			"UniqueGoogleMapURL": uniqueGoogleMapURL.Text,
-			"UniqueReportURL":    uniqueReportURL.Text,
-			"MainImagePath":      mainImagePath.Text,
-			"Description":        descriptionEntry.Text,
-			"Costs":              costsEntry.Text,
-			"Transportation":     transportationEntry.Text,
-			"Equipment":          equipmentEntry.Text,
-			"Accommodation":      accommodationEntry.Text,
-			"RelatedEvents":      getRelatedEventsData(),
-			"SubImages":          helpers.GetSubImageData(subImageContainer),
+
+		googleMap := googleMapField.location()
+
+		documentLocation, err := location.location()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
 		}
 
-		jsonData, err := json.MarshalIndent(tripData, "", "  ")
+		documentClassification, err := classification.values()
 		if err != nil {
 			dialog.ShowError(err, window)
 			return
 		}
 
-		outputFolder := "output/trips"
-		if _, err := os.Stat(outputFolder); os.IsNotExist(err) {
-			err = os.MkdirAll(outputFolder, os.ModePerm)
-			if err != nil {
-				dialog.ShowError(fmt.Errorf("Failed to create output folder: %v", err), window)
-				return
-			}
+		itineraryDays, err := itinerary.values()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
 		}
 
-		fileName := filepath.Join(outputFolder, fmt.Sprintf("%s_trip.json", uniqueTripID.Text))
-		err = os.WriteFile(fileName, jsonData, 0644)
+		accommodationRecords, err := accommodations.values()
 		if err != nil {
 			dialog.ShowError(err, window)
 			return
 		}
+		if len(accommodationRecords) == 0 && accommodationEntry.Text == "" {
+			dialog.ShowError(fmt.Errorf("Please fill all required fields"), window)
+			return
+		}
+		helpers.LinkAccommodations(accommodationRecords, itineraryDays)
+		accommodationMarkdown := accommodationEntry.Text
+		if len(accommodationRecords) > 0 {
+			accommodationMarkdown = helpers.RenderAccommodationMarkdown(accommodationRecords, accommodationEntry.Text)
+		}
 
-		dialog.ShowInformation("Success", fmt.Sprintf("Trip saved as %s", fileName), window)
+		transportLegs, err := transport.values()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		if len(transportLegs) == 0 && transportationEntry.Text == "" {
+			dialog.ShowError(fmt.Errorf("Please fill all required fields"), window)
+			return
+		}
+		transportationMarkdown, transportationHTML := transportationEntry.Text, ""
+		if len(transportLegs) > 0 {
+			transportationMarkdown = helpers.RenderTransportMarkdown(transportLegs, transportationEntry.Text)
+			transportationHTML = helpers.RenderTransportHTML(transportLegs)
+		}
+
+		gearList, err := gear.values()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		equipmentMarkdown := equipmentEntry.Text
+		if gearList != nil {
+			equipmentMarkdown = helpers.RenderGearMarkdown(*gearList, equipmentEntry.Text)
+		}
+
+		documentCosts, err := costBreakdown.values()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		costsMarkdown := costsEntry.Text
+		if documentCosts != nil {
+			costsMarkdown = helpers.RenderCostsMarkdown(*documentCosts, costsEntry.Text)
+		}
+
+		tripData := map[string]interface{}{
+			"CreationDate":         creationDate.Text,
+			"EntryType":            entryType.Text,
+			"TripName":             tripName.Text,
+			"Author":               strings.TrimSpace(author.Text),
+			"Participants":         participants.values(),
+			"TripStartDate":        tripStartDate.Text,
+			"TripEndDate":          tripEndDate.Text,
+			"UniqueTripID":         uniqueTripID.Text,
+			"UniqueGoogleMapURL":   uniqueGoogleMapURL.Text,
+			"GoogleMap":            googleMap,
+			"UniqueReportURL":      uniqueReportURL.Text,
+			"MainImagePath":        mainImagePath.Text,
+			"MainImageAltText":     mainImageAltText.Text,
+			"MainImageCaption":     mainImageCaption.Text,
+			"Location":             documentLocation,
+			"Activity":             documentClassification.Activity,
+			"Difficulty":           documentClassification.Difficulty,
+			"Duration":             helpers.FormatDuration(documentClassification.DurationMinutes),
+			"DurationMinutes":      documentClassification.DurationMinutes,
+			"DistanceKm":           documentClassification.DistanceKm,
+			"Tags":                 documentClassification.Tags,
+			"GPXTrackPath":         gpxTrackPath.Text,
+			"ElevationProfilePath": elevationProfilePath.Text,
+			"TrackStats":           trackStats,
+			"Itinerary":            itineraryDays,
+			"Description":          descriptionEntry.Text,
+			"Costs":                costsMarkdown,
+			"CostNotes":            costsEntry.Text,
+			"CostBreakdown":        documentCosts,
+			"Transportation":       transportationMarkdown,
+			"TransportationNotes":  transportationEntry.Text,
+			"TransportationHTML":   transportationHTML,
+			"TransportLegs":        transportLegs,
+			"TravelTime":           helpers.FormatDuration(helpers.TravelMinutes(transportLegs)),
+			"TravelMinutes":        helpers.TravelMinutes(transportLegs),
+			"Equipment":            equipmentMarkdown,
+			"EquipmentNotes":       equipmentEntry.Text,
+			"Gear":                 gearList,
+			"Accommodation":        accommodationMarkdown,
+			"AccommodationNotes":   accommodationEntry.Text,
+			"Accommodations":       accommodationRecords,
+			"RelatedEvents":        getRelatedEventsData(),
+			"SubImages":            helpers.GetSubImageData(subImages.container),
+			"Attachments":          attachments.attachments(),
+		}
+
+		// write runs once the files are in place, with their final URLs.
+		write := func() {
+			tripData["MainImagePath"] = mainImagePath.Text
+			tripData["GPXTrackPath"] = gpxTrackPath.Text
+			tripData["ElevationProfilePath"] = elevationProfilePath.Text
+			tripData["SubImages"] = helpers.GetSubImageData(subImages.container)
+			tripData["Attachments"] = attachments.attachments()
+
+			jsonData, err := helpers.MarshalDocument(tripData)
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+
+			outputFolder := "output/trips"
+			if _, err := os.Stat(outputFolder); os.IsNotExist(err) {
+				err = os.MkdirAll(outputFolder, os.ModePerm)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Failed to create output folder: %v", err), window)
+					return
+				}
+			}
+
+			fileName := filepath.Join(outputFolder, fmt.Sprintf("%s_trip.json", uniqueTripID.Text))
+			err = os.WriteFile(fileName, jsonData, 0644)
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			helpers.DocumentPublished(fileName)
+
+			dialog.ShowInformation("Success", fmt.Sprintf("Trip saved as %s", fileName), window)
+		}
+
+		// Move files uploaded before the ID was set under the final ID,
+		// only once publishing is confirmed
+		save := func() {
+			stagedFields := append([]*widget.Entry{mainImagePath, gpxTrackPath, elevationProfilePath}, subImages.urlFields()...)
+			stagedFields = append(stagedFields, attachments.urlFields()...)
+			relocate := prefix.relocate(stagedFields...)
+			uploads.start(fmt.Sprintf("Publish %s", uniqueTripID.Text), func(ctx context.Context, progress helpers.ProgressFunc) error {
+				if err := relocate(ctx, progress); err != nil {
+					return err
+				}
+				onMain(write)
+				return nil
+			})
+		}
+
+		if warnings := helpers.AccessibilityWarnings(tripData); len(warnings) > 0 {
+			message := fmt.Sprintf("%s\n\nPublish anyway?", strings.Join(warnings, "\n"))
+			dialog.ShowConfirm("Accessibility warnings", message, func(publish bool) {
+				if publish {
+					save()
+				}
+			}, window)
+			return
+		}
+		save()
 	})
 
 	content := container.NewVBox(
@@ -265,20 +441,89 @@ func NewTripTab(window fyne.Window) *container.TabItem {
 		widget.NewLabel("Trip Start Date*:"), tripStartDate,
 		widget.NewLabel("Trip End Date*:"), tripEndDate,
 		widget.NewLabel("Unique Trip ID*:"), uniqueTripID,
-		widget.NewLabel("Unique Google Map URL:"), uniqueGoogleMapURL,
+		widget.NewLabel("Unique Google Map URL:"), googleMapField.content,
 		widget.NewLabel("Unique Report URL:"), uniqueReportURL,
-		widget.NewLabel("Main Image:"), container.NewHBox(mainImagePath, mainImageUploadButton),
+		widget.NewLabel("Author:"), author,
+		widget.NewLabel("Participants:"), participants.content,
+		widget.NewLabel("Classification:"), classification.content,
+		widget.NewLabel("Location:"), location.content,
+		prefillFromMetadata,
+		widget.NewLabel("Main Image:"), container.NewHBox(mainImagePath, mainImageUploadButton, newPreviewButton(window, mainImagePath)),
+		widget.NewLabel("Main Image Alt Text:"), mainImageAltText,
+		widget.NewLabel("Main Image Caption:"), mainImageCaption,
+		widget.NewLabel("GPX Track:"), container.NewHBox(gpxTrackPath, gpxUploadButton),
+		widget.NewLabel("Elevation Profile:"), container.NewHBox(elevationProfilePath, newPreviewButton(window, elevationProfilePath)),
+		widget.NewLabel("Itinerary:"), itinerary.content,
 		widget.NewLabel("Description*:"), container.NewBorder(descriptionToolbar, nil, nil, nil, container.NewVBox(descriptionEntry, description)),
 		widget.NewLabel("Costs:"), container.NewBorder(costsToolbar, nil, nil, nil, container.NewVBox(costsEntry, costs)),
+		widget.NewLabel("Cost Breakdown:"), costBreakdown.content,
 		widget.NewLabel("Transportation*:"), container.NewBorder(transportationToolbar, nil, nil, nil, container.NewVBox(transportationEntry, transportation)),
+		widget.NewLabel("Transport Legs:"), transport.content,
 		widget.NewLabel("Equipment:"), container.NewBorder(equipmentToolbar, nil, nil, nil, container.NewVBox(equipmentEntry, equipment)),
+		widget.NewLabel("Gear List:"), gear.content,
 		widget.NewLabel("Accommodation*:"), container.NewBorder(accommodationToolbar, nil, nil, nil, container.NewVBox(accommodationEntry, accommodation)),
+		widget.NewLabel("Accommodation Records:"), accommodations.content,
 		widget.NewLabel("Related Events:"), relatedEventsContainer, addEventButton,
-		widget.NewLabel("Sub Images:"), subImageContainer, addSubImageButton,
+		widget.NewLabel("Sub Images:"), subImages.content,
+		widget.NewLabel("Attachments:"), attachments.content,
+		widget.NewLabel("Uploads:"), uploads.content,
 		layout.NewSpacer(),
 		publishButton,
 	)
 
+	// Fill the form with a published trip for editing
+	load := func(document helpers.Document) {
+		loadEntries(document, map[string]*widget.Entry{
+			"CreationDate":         creationDate,
+			"TripName":             tripName,
+			"TripStartDate":        tripStartDate,
+			"TripEndDate":          tripEndDate,
+			"UniqueTripID":         uniqueTripID,
+			"UniqueGoogleMapURL":   uniqueGoogleMapURL,
+			"UniqueReportURL":      uniqueReportURL,
+			"MainImagePath":        mainImagePath,
+			"MainImageAltText":     mainImageAltText,
+			"MainImageCaption":     mainImageCaption,
+			"GPXTrackPath":         gpxTrackPath,
+			"ElevationProfilePath": elevationProfilePath,
+			"Description":          descriptionEntry,
+			"Costs":                costsEntry,
+			"Transportation":       transportationEntry,
+			"Equipment":            equipmentEntry,
+			"Accommodation":        accommodationEntry,
+		})
+		trackStats = nil
+		decodeField(document, "TrackStats", &trackStats)
+
+		relatedEventsContainer.RemoveAll()
+		var relatedEvents []map[string]interface{}
+		decodeField(document, "RelatedEvents", &relatedEvents)
+		for _, relatedEvent := range relatedEvents {
+			eventName, eventDescription, eventURL := addRelatedEvent()
+			eventName.SetText(stringField(relatedEvent, "Name"))
+			eventDescription.SetText(stringField(relatedEvent, "Description"))
+			eventURL.SetText(stringField(relatedEvent, "URL"))
+		}
+
+		classification.load(document)
+		author.SetText(document.Text("Author"))
+		participants.load(document)
+		if accommodations.load(document) {
+			accommodationEntry.SetText(document.Text("AccommodationNotes"))
+		}
+		itinerary.load(document)
+		if transport.load(document) {
+			transportationEntry.SetText(document.Text("TransportationNotes"))
+		}
+		if gear.load(document) {
+			equipmentEntry.SetText(document.Text("EquipmentNotes"))
+		}
+		if costBreakdown.load(document) {
+			costsEntry.SetText(document.Text("CostNotes"))
+		}
+		loadSections(document, prefix, location, subImages, attachments)
+	}
+
 	scrollableContent := container.NewVScroll(content)
-	return container.NewTabItem("Trip", scrollableContent)
+	return container.NewTabItem("Trip", scrollableContent), load
 }
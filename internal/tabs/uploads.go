@@ -0,0 +1,131 @@
+package tabs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/helpers"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	maxParallelUploads = 4
+	uploadAttempts     = 4
+	uploadRetryDelay   = time.Second
+)
+
+type uploadFunc func(ctx context.Context, progress helpers.ProgressFunc) error
+
+// onMain runs fn, which updates widgets from a background goroutine, on the
+// UI thread. Fyne 2.5 has no way to queue work onto its event loop and
+// tolerates updates from any goroutine, so fn runs right away; with Fyne 2.6
+// this becomes fyne.Do(fn). Every widget update of a task goes through here.
+func onMain(fn func()) {
+	fn()
+}
+
+// uploadManager runs uploads in the background, one row per file with a
+// progress bar and a cancel button. Tasks run off the UI thread and report
+// into their row and the form through onMain.
+type uploadManager struct {
+	window  fyne.Window
+	list    *fyne.Container
+	slots   chan struct{}
+	content fyne.CanvasObject
+
+	mu      sync.Mutex
+	pending int
+	rows    map[fyne.CanvasObject]bool // finished rows, removed by "Clear Finished"
+}
+
+func newUploadManager(window fyne.Window) *uploadManager {
+	u := &uploadManager{
+		window: window,
+		list:   container.NewVBox(),
+		slots:  make(chan struct{}, maxParallelUploads),
+		rows:   make(map[fyne.CanvasObject]bool),
+	}
+
+	clearButton := widget.NewButton("Clear Finished", func() {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		for row := range u.rows {
+			u.list.Remove(row)
+		}
+		u.rows = make(map[fyne.CanvasObject]bool)
+	})
+	u.content = container.NewVBox(u.list, clearButton)
+	return u
+}
+
+// start queues an upload or another long-running S3 task. Failed attempts are retried with exponential
+// backoff and the final error is shown in a dialog.
+func (u *uploadManager) start(name string, run uploadFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	progress := widget.NewProgressBar()
+	status := widget.NewLabel("Queued")
+	cancelButton := widget.NewButton("Cancel", cancel)
+	row := container.NewBorder(nil, nil, widget.NewLabel(name), container.NewHBox(status, cancelButton), progress)
+	u.list.Add(row)
+
+	u.mu.Lock()
+	u.pending++
+	u.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		setProgress := func(value float64) {
+			onMain(func() { progress.SetValue(value) })
+		}
+
+		var err error
+		select {
+		case u.slots <- struct{}{}:
+			onMain(func() { status.SetText("Running") })
+			err = helpers.RetryWithBackoff(ctx, uploadAttempts, uploadRetryDelay, func() error {
+				return run(ctx, setProgress)
+			}, func(attempt int, err error) {
+				onMain(func() {
+					progress.SetValue(0)
+					status.SetText(fmt.Sprintf("Retrying (%d/%d)", attempt, uploadAttempts))
+				})
+			})
+			<-u.slots
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		onMain(func() {
+			cancelButton.Disable()
+			switch {
+			case err == nil:
+				progress.SetValue(1)
+				status.SetText("Done")
+			case ctx.Err() != nil:
+				status.SetText("Cancelled")
+			default:
+				status.SetText("Failed")
+				dialog.ShowError(fmt.Errorf("%s failed: %v", name, err), u.window)
+			}
+		})
+
+		u.mu.Lock()
+		u.pending--
+		u.rows[row] = true
+		u.mu.Unlock()
+	}()
+}
+
+// busy reports whether uploads are still queued or running.
+func (u *uploadManager) busy() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.pending > 0
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
+	"lambda-hikes-trailfinder-json-publisher-go-app/internal/commands"
 	"lambda-hikes-trailfinder-json-publisher-go-app/internal/tabs"
 
 	"fyne.io/fyne/v2"
@@ -9,15 +13,28 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := commands.Run(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	myApp := app.New()
 	myWindow := myApp.NewWindow("Event and Report Publisher")
 
-	reportTab := tabs.NewReportTab(myWindow)
-	eventTab := tabs.NewEventTab(myWindow)
-	tripTab := tabs.NewTripTab(myWindow)
-	tabs := container.NewAppTabs(reportTab, eventTab, tripTab)
+	reportTab, loadReport := tabs.NewReportTab(myWindow)
+	eventTab, loadEvent := tabs.NewEventTab(myWindow)
+	tripTab, loadTrip := tabs.NewTripTab(myWindow)
+	appTabs := container.NewAppTabs(reportTab, eventTab, tripTab)
+	appTabs.Append(tabs.NewLibraryTab(myWindow, appTabs, map[string]tabs.Editor{
+		"Report": {Tab: reportTab, Load: loadReport},
+		"Event":  {Tab: eventTab, Load: loadEvent},
+		"Trip":   {Tab: tripTab, Load: loadTrip},
+	}))
 
-	myWindow.SetContent(tabs)
+	myWindow.SetContent(appTabs)
 	myWindow.Resize(fyne.NewSize(600, 800))
 	myWindow.ShowAndRun()
 }